@@ -0,0 +1,140 @@
+package layer4
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UniversalApplicability is an applicability tag that makes an assessment apply to every target,
+// regardless of the target's applicability and the assessment's Applicability_Matcher,
+// so that universal assessments do not need to enumerate every tag
+const UniversalApplicability = "*"
+
+// ApplicabilityMatcher determines whether an assessment applies to a target,
+// based on the assessment's applicability tags and the target's applicability tags
+type ApplicabilityMatcher interface {
+	Matches(assessmentTags, targetTags []string) bool
+}
+
+// ApplicabilityExtractor derives a target's applicability tags by inspecting the target data itself
+type ApplicabilityExtractor func(targetData interface{}) []string
+
+// ExactMatcher is the default ApplicabilityMatcher.
+// It matches when any assessment tag is identical to any target tag.
+type ExactMatcher struct{}
+
+// Matches returns true if any assessment tag is identical to any target tag
+func (ExactMatcher) Matches(assessmentTags, targetTags []string) bool {
+	for _, aa := range assessmentTags {
+		for _, ta := range targetTags {
+			if aa == ta {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HierarchicalMatcher is an ApplicabilityMatcher for tags that are paths in a taxonomy, such as "cloud/aws/ec2".
+// It matches when any target tag is identical to an assessment tag or is a descendant of it,
+// so an assessment tagged "cloud/aws" applies to a target tagged "cloud/aws/ec2", but not to "cloud/azure"
+// or "cloud/aws-gov". A target tagged with an ancestor, such as "cloud", does not match.
+type HierarchicalMatcher struct {
+	Separator string // Separator divides the segments of each tag; defaults to "/"
+}
+
+// Matches returns true if any target tag is identical to, or a descendant of, any assessment tag
+func (m HierarchicalMatcher) Matches(assessmentTags, targetTags []string) bool {
+	separator := m.Separator
+	if separator == "" {
+		separator = "/"
+	}
+	for _, aa := range assessmentTags {
+		ancestor := strings.TrimSuffix(aa, separator) + separator
+		for _, ta := range targetTags {
+			if aa == ta || strings.HasPrefix(ta, ancestor) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isApplicable uses the assessment's matcher to determine whether the
+// assessment applies to the provided target applicability
+func (a *Assessment) isApplicable(targetApplicability []string) bool {
+	_, applicable := a.matchApplicability(targetApplicability)
+	return applicable
+}
+
+// matchApplicability determines whether the assessment applies to the provided target applicability,
+// and returns the assessment's applicability values that individually match the target.
+// Exclusion wins: if any target tag is identical to a NotApplicable_To tag, the assessment does not apply,
+// even when its Applicability matched or contains UniversalApplicability.
+func (a *Assessment) matchApplicability(targetApplicability []string) (matched []string, applicable bool) {
+	return a.matchApplicabilityWithMatcher(targetApplicability, a.Applicability_Matcher)
+}
+
+// matchApplicabilityWithMatcher behaves like matchApplicability, using the provided matcher in place of the assessment's own
+func (a *Assessment) matchApplicabilityWithMatcher(targetApplicability []string, matcher ApplicabilityMatcher) (matched []string, applicable bool) {
+	if a.isExcluded(targetApplicability) {
+		return nil, false
+	}
+	if a.isUniversal() {
+		return []string{UniversalApplicability}, true
+	}
+	if matcher == nil {
+		matcher = ExactMatcher{}
+	}
+	if !matcher.Matches(a.Applicability, targetApplicability) {
+		return nil, false
+	}
+	for _, aa := range a.Applicability {
+		if matcher.Matches([]string{aa}, targetApplicability) {
+			matched = append(matched, aa)
+		}
+	}
+	return matched, true
+}
+
+// isUniversal returns true if the assessment's Applicability contains UniversalApplicability
+func (a *Assessment) isUniversal() bool {
+	for _, aa := range a.Applicability {
+		if aa == UniversalApplicability {
+			return true
+		}
+	}
+	return false
+}
+
+// isExcluded returns true if any target tag is identical to one of the assessment's NotApplicable_To tags
+func (a *Assessment) isExcluded(targetApplicability []string) bool {
+	for _, excluded := range a.NotApplicable_To {
+		for _, ta := range targetApplicability {
+			if excluded == ta {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateApplicability returns an error if any applicability tag is empty,
+// since an empty tag would otherwise match any other empty tag
+func validateApplicability(tags []string) error {
+	for _, tag := range tags {
+		if tag == "" {
+			return fmt.Errorf("applicability must not contain empty values, but got: %q", tags)
+		}
+	}
+	return nil
+}
+
+// ApplicabilitySummary records how a target's applicability matched a control's assessments,
+// to help explain why an evaluation ran fewer assessments than expected
+type ApplicabilitySummary struct {
+	Provided    []string `json:"provided" yaml:"provided"`       // Provided is the applicability of the target, after any Applicability_Extractor was consulted
+	Matched     int      `json:"matched" yaml:"matched"`         // Matched is the number of assessments that applied to the target
+	Skipped     int      `json:"skipped" yaml:"skipped"`         // Skipped is the number of assessments skipped because they did not apply to the target
+	Assessments int      `json:"assessments" yaml:"assessments"` // Assessments is the total number of assessments considered
+}
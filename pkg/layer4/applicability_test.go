@@ -0,0 +1,356 @@
+package layer4
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// prefixMatcher is a custom ApplicabilityMatcher that matches target tags beginning with an assessment tag
+type prefixMatcher struct{}
+
+func (prefixMatcher) Matches(assessmentTags, targetTags []string) bool {
+	for _, aa := range assessmentTags {
+		for _, ta := range targetTags {
+			if strings.HasPrefix(ta, aa) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestIsApplicable(t *testing.T) {
+	tests := []struct {
+		testName            string
+		matcher             ApplicabilityMatcher
+		applicability       []string
+		targetApplicability []string
+		expected            bool
+	}{
+		{
+			testName:            "Default matcher with identical tag",
+			applicability:       []string{"linux", "windows"},
+			targetApplicability: []string{"windows"},
+			expected:            true,
+		},
+		{
+			testName:            "Default matcher with no identical tag",
+			applicability:       []string{"linux"},
+			targetApplicability: []string{"linux-arm"},
+			expected:            false,
+		},
+		{
+			testName:            "Custom matcher with prefixed tag",
+			matcher:             prefixMatcher{},
+			applicability:       []string{"linux"},
+			targetApplicability: []string{"linux-arm"},
+			expected:            true,
+		},
+		{
+			testName:            "Custom matcher with unrelated tag",
+			matcher:             prefixMatcher{},
+			applicability:       []string{"linux"},
+			targetApplicability: []string{"windows"},
+			expected:            false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			a := Assessment{Applicability: test.applicability, Applicability_Matcher: test.matcher}
+			if a.isApplicable(test.targetApplicability) != test.expected {
+				t.Errorf("expected isApplicable to return %t", test.expected)
+			}
+		})
+	}
+}
+
+func TestHierarchicalMatcher(t *testing.T) {
+	tests := []struct {
+		testName      string
+		separator     string
+		assessmentTag string
+		targetTag     string
+		expected      bool
+	}{
+		{testName: "Identical", assessmentTag: "cloud/aws", targetTag: "cloud/aws", expected: true},
+		{testName: "Descendant", assessmentTag: "cloud/aws", targetTag: "cloud/aws/ec2", expected: true},
+		{testName: "Distant descendant", assessmentTag: "cloud", targetTag: "cloud/aws/ec2", expected: true},
+		{testName: "Trailing separator", assessmentTag: "cloud/aws/", targetTag: "cloud/aws/ec2", expected: true},
+		{testName: "Ancestor", assessmentTag: "cloud/aws/ec2", targetTag: "cloud/aws", expected: false},
+		{testName: "Sibling", assessmentTag: "cloud/aws", targetTag: "cloud/azure", expected: false},
+		{testName: "Sibling sharing a prefix", assessmentTag: "cloud/aws", targetTag: "cloud/aws-gov", expected: false},
+		{testName: "Custom separator", separator: ".", assessmentTag: "cloud.aws", targetTag: "cloud.aws.ec2", expected: true},
+		{testName: "Custom separator ignores the default", separator: ".", assessmentTag: "cloud", targetTag: "cloud/aws", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			matcher := HierarchicalMatcher{Separator: test.separator}
+			if matcher.Matches([]string{test.assessmentTag}, []string{test.targetTag}) != test.expected {
+				t.Errorf("expected %q to match %q: %t", test.assessmentTag, test.targetTag, test.expected)
+			}
+		})
+	}
+
+	a := Assessment{Applicability: []string{"cloud/aws", "cloud/gcp"}, Applicability_Matcher: HierarchicalMatcher{}}
+	matched, applicable := a.matchApplicability([]string{"cloud/aws/ec2"})
+	if !applicable || !reflect.DeepEqual(matched, []string{"cloud/aws"}) {
+		t.Errorf("expected only cloud/aws to match, got %v (%t)", matched, applicable)
+	}
+}
+
+func TestNotApplicableTo(t *testing.T) {
+	tests := []struct {
+		testName            string
+		matcher             ApplicabilityMatcher
+		exclusions          []string
+		targetApplicability []string
+		expected            bool
+	}{
+		{
+			testName:            "Exclusion overrides a matching inclusion",
+			exclusions:          []string{"gov"},
+			targetApplicability: []string{"us-east", "gov"},
+			expected:            false,
+		},
+		{
+			testName:            "Exclusion overrides a custom matcher",
+			matcher:             prefixMatcher{},
+			exclusions:          []string{"us-gov"},
+			targetApplicability: []string{"us-gov"},
+			expected:            false,
+		},
+		{
+			testName:            "Unrelated exclusion",
+			exclusions:          []string{"gov"},
+			targetApplicability: []string{"us-east"},
+			expected:            true,
+		},
+		{
+			testName:            "Exclusion without a matching inclusion",
+			exclusions:          []string{"gov"},
+			targetApplicability: []string{"eu-west"},
+			expected:            false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			a := Assessment{Applicability: []string{"us-east", "us"}, NotApplicable_To: test.exclusions, Applicability_Matcher: test.matcher}
+			matched, applicable := a.matchApplicability(test.targetApplicability)
+			if applicable != test.expected {
+				t.Errorf("expected matchApplicability to return %t", test.expected)
+			}
+			if !applicable && matched != nil {
+				t.Errorf("expected no matched applicability for an inapplicable test, got %v", matched)
+			}
+		})
+	}
+
+	c := &ControlEvaluation{
+		Name:                    "exclusion",
+		Control_Id:              "exclusion",
+		Prefilter_Applicability: true,
+		Assessments: []*Assessment{{
+			Requirement_Id:   "exclusion",
+			Description:      "exclusion",
+			Applicability:    testingApplicability,
+			NotApplicable_To: []string{"excluded"},
+			Steps:            []AssessmentStep{failingAssessmentStep},
+		}},
+	}
+	c.Evaluate(nil, append([]string{"excluded"}, testingApplicability...), false)
+	if c.Assessments[0].Result != NotApplicable || c.Assessments[0].Steps_Executed != 0 {
+		t.Errorf("expected the excluded test to be %s without running, got %s after %d steps", NotApplicable, c.Assessments[0].Result, c.Assessments[0].Steps_Executed)
+	}
+
+	invalid := &Assessment{Requirement_Id: "invalid", Description: "invalid", Applicability: testingApplicability, NotApplicable_To: []string{""}, Steps: []AssessmentStep{passingAssessmentStep}}
+	if err := invalid.validate(); err == nil {
+		t.Errorf("expected an error for an empty exclusion tag")
+	}
+}
+
+func TestControlEvaluationPropagatesMatcher(t *testing.T) {
+	a := &Assessment{
+		Requirement_Id: "prefixed",
+		Description:    "prefixed assessment",
+		Applicability:  []string{"linux"},
+		Steps:          []AssessmentStep{passingAssessmentStep},
+	}
+	c := &ControlEvaluation{
+		Assessments:           []*Assessment{a},
+		Applicability_Matcher: prefixMatcher{},
+	}
+	c.Evaluate(nil, []string{"linux-arm"}, false)
+
+	if a.Steps_Executed != 1 {
+		t.Errorf("expected the control matcher to make the assessment applicable, but it executed %d steps", a.Steps_Executed)
+	}
+	if c.Result != Passed {
+		t.Errorf("expected %v, got %v", Passed, c.Result)
+	}
+}
+
+func TestMatchedApplicability(t *testing.T) {
+	a := &Assessment{
+		Requirement_Id: "matched",
+		Description:    "matched assessment",
+		Applicability:  []string{"linux", "windows", "macos"},
+		Steps:          []AssessmentStep{passingAssessmentStep},
+	}
+	c := &ControlEvaluation{Assessments: []*Assessment{a}}
+	c.Evaluate(nil, []string{"windows", "arm"}, false)
+
+	if len(a.Matched_Applicability) != 1 || a.Matched_Applicability[0] != "windows" {
+		t.Fatalf("expected matched applicability to be [windows], got %v", a.Matched_Applicability)
+	}
+
+	serialized, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("unexpected error serializing assessment: %v", err)
+	}
+	if !strings.Contains(string(serialized), `"matched-applicability":["windows"]`) {
+		t.Errorf("expected serialized assessment to include the matched applicability, got %s", serialized)
+	}
+}
+
+func TestUniversalApplicability(t *testing.T) {
+	tests := []struct {
+		testName            string
+		matcher             ApplicabilityMatcher
+		exclusions          []string
+		targetApplicability []string
+		expected            bool
+	}{
+		{testName: "Unrelated target tag", targetApplicability: []string{"windows"}, expected: true},
+		{testName: "No target tags", targetApplicability: nil, expected: true},
+		{testName: "Custom matcher", matcher: HierarchicalMatcher{}, targetApplicability: []string{"cloud/aws"}, expected: true},
+		{testName: "Exclusion wins", exclusions: []string{"gov"}, targetApplicability: []string{"gov"}, expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			a := Assessment{Applicability: []string{UniversalApplicability}, Applicability_Matcher: test.matcher, NotApplicable_To: test.exclusions}
+			matched, applicable := a.matchApplicability(test.targetApplicability)
+			if applicable != test.expected {
+				t.Errorf("expected matchApplicability to return %t", test.expected)
+			}
+			if applicable && !reflect.DeepEqual(matched, []string{UniversalApplicability}) {
+				t.Errorf("expected the universal tag to be recorded as matched, got %v", matched)
+			}
+		})
+	}
+
+	a, err := NewAssessment("universal", "applies everywhere", []string{UniversalApplicability}, []AssessmentStep{passingAssessmentStep})
+	if err != nil {
+		t.Fatalf("expected a universal assessment to pass precheck, got %v", err)
+	}
+	c := &ControlEvaluation{Name: "universal", Control_Id: "universal", Assessments: []*Assessment{a}}
+	if err := c.TryEvaluate(nil, []string{"anything"}, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Result != Passed || c.Applicability_Summary.Matched != 1 {
+		t.Errorf("expected the universal assessment to run and pass, got %s with %d matched", c.Result, c.Applicability_Summary.Matched)
+	}
+}
+
+func TestEmptyApplicability(t *testing.T) {
+	tests := []struct {
+		name                string
+		applicability       []string
+		targetApplicability []string
+	}{
+		{name: "Empty first assessment tag", applicability: []string{"", "tlp_green"}, targetApplicability: []string{"tlp_green"}},
+		{name: "Empty last assessment tag", applicability: []string{"tlp_green", ""}, targetApplicability: []string{"tlp_green"}},
+		{name: "Empty first target tag", applicability: []string{"tlp_green"}, targetApplicability: []string{"", "tlp_green"}},
+		{name: "Empty last target tag", applicability: []string{"tlp_green"}, targetApplicability: []string{"tlp_green", ""}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &ControlEvaluation{Assessments: []*Assessment{
+				{Requirement_Id: "empty", Description: "empty", Applicability: test.applicability, Steps: []AssessmentStep{passingAssessmentStep}},
+			}}
+			err := c.TryEvaluate(nil, test.targetApplicability, false)
+			if err == nil {
+				t.Errorf("Expected an error, but got nil")
+			}
+			if c.Result != Unknown {
+				t.Errorf("Expected Result to be %v, but got %v", Unknown, c.Result)
+			}
+			if !strings.Contains(c.Message, "empty") {
+				t.Errorf("Expected the message to describe the empty tag, but got %q", c.Message)
+			}
+			if c.Assessments[0].Steps_Executed != 0 {
+				t.Errorf("Expected no steps to be run")
+			}
+		})
+	}
+}
+
+func TestApplicabilityExtractor(t *testing.T) {
+	type repository struct {
+		Name string
+		Tags []string
+	}
+	extractor := func(targetData interface{}) []string {
+		if repo, ok := targetData.(repository); ok {
+			return repo.Tags
+		}
+		return nil
+	}
+	tests := []struct {
+		name                string
+		targetApplicability []string
+		expectedResult      Result
+	}{
+		{name: "Derived from target data", targetApplicability: nil, expectedResult: Passed},
+		{name: "Explicit applicability takes precedence", targetApplicability: []string{"tlp_red"}, expectedResult: NotApplicable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &ControlEvaluation{Applicability_Extractor: extractor, Prefilter_Applicability: true}
+			c.AddAssessment("extracted", "extracted", []string{"tlp_green"}, []AssessmentStep{passingAssessmentStep})
+			c.Evaluate(repository{Name: "sci", Tags: []string{"tlp_green"}}, test.targetApplicability, false)
+			if c.Assessments[0].Result != test.expectedResult {
+				t.Errorf("Expected Result to be %v, but got %v", test.expectedResult, c.Assessments[0].Result)
+			}
+		})
+	}
+}
+
+func TestApplicabilitySummary(t *testing.T) {
+	newControl := func() *ControlEvaluation {
+		return &ControlEvaluation{
+			Name:       "summary",
+			Control_Id: "summary",
+			Assessments: []*Assessment{
+				{Requirement_Id: "first", Description: "first", Applicability: []string{"tlp-green"}, Steps: []AssessmentStep{passingAssessmentStep}},
+				{Requirement_Id: "second", Description: "second", Applicability: []string{"tlp-red"}, Steps: []AssessmentStep{passingAssessmentStep}},
+			},
+		}
+	}
+
+	t.Run("No matches", func(t *testing.T) {
+		c := newControl()
+		c.Evaluate(nil, []string{"tlp-clear"}, false)
+
+		expected := ApplicabilitySummary{Provided: []string{"tlp-clear"}, Matched: 0, Skipped: 2, Assessments: 2}
+		if !reflect.DeepEqual(c.Applicability_Summary, expected) {
+			t.Errorf("Expected %+v, but got %+v", expected, c.Applicability_Summary)
+		}
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(string(data), `"applicability-summary":{"provided":["tlp-clear"],"matched":0,"skipped":2,"assessments":2}`) {
+			t.Errorf("Expected the summary to be serialized, but got %s", data)
+		}
+	})
+	t.Run("Partial match", func(t *testing.T) {
+		c := newControl()
+		c.Evaluate(nil, []string{"tlp-green", "tlp-amber"}, false)
+
+		if c.Applicability_Summary.Matched != 1 || c.Applicability_Summary.Skipped != 1 || c.Applicability_Summary.Assessments != 2 {
+			t.Errorf("Expected 1 of 2 assessments to match, but got %+v", c.Applicability_Summary)
+		}
+	})
+}
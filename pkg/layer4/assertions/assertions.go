@@ -0,0 +1,105 @@
+// Package assertions provides helpers for writing layer4 assessment steps.
+// Each helper returns the (Result, string) pair that an AssessmentStep returns,
+// so that common checks produce consistent messages across step authors.
+package assertions
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/revanite-io/sci/pkg/layer4"
+)
+
+// Equal passes if actual and expected are deeply equal
+func Equal(actual interface{}, expected interface{}, fieldName string) (layer4.Result, string) {
+	if !reflect.DeepEqual(actual, expected) {
+		return layer4.Failed, fmt.Sprintf("expected %s to be %v, but got %v", fieldName, expected, actual)
+	}
+	return layer4.Passed, fmt.Sprintf("%s is %v", fieldName, expected)
+}
+
+// NotEqual passes if actual and unexpected are not deeply equal
+func NotEqual(actual interface{}, unexpected interface{}, fieldName string) (layer4.Result, string) {
+	if reflect.DeepEqual(actual, unexpected) {
+		return layer4.Failed, fmt.Sprintf("expected %s not to be %v", fieldName, unexpected)
+	}
+	return layer4.Passed, fmt.Sprintf("%s is not %v", fieldName, unexpected)
+}
+
+// NotEmpty passes if the value is not nil and not the zero value of its type.
+// Strings, slices, maps, arrays, and channels must have a length greater than zero.
+func NotEmpty(value interface{}, fieldName string) (layer4.Result, string) {
+	if isEmpty(value) {
+		return layer4.Failed, fmt.Sprintf("expected %s not to be empty", fieldName)
+	}
+	return layer4.Passed, fmt.Sprintf("%s is not empty", fieldName)
+}
+
+// Contains passes if the collection contains the element. A string collection must contain the element
+// as a substring, a slice or array must have an equal item, and a map must have the element as a key.
+func Contains(collection interface{}, element interface{}, fieldName string) (layer4.Result, string) {
+	found, err := contains(collection, element)
+	if err != nil {
+		return layer4.Unknown, fmt.Sprintf("could not check whether %s contains %v: %v", fieldName, element, err)
+	}
+	if !found {
+		return layer4.Failed, fmt.Sprintf("expected %s to contain %v", fieldName, element)
+	}
+	return layer4.Passed, fmt.Sprintf("%s contains %v", fieldName, element)
+}
+
+// True passes if the condition is true
+func True(condition bool, fieldName string) (layer4.Result, string) {
+	if !condition {
+		return layer4.Failed, fmt.Sprintf("expected %s to be true", fieldName)
+	}
+	return layer4.Passed, fmt.Sprintf("%s is true", fieldName)
+}
+
+// isEmpty returns true if the value is nil or the zero value of its type
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array, reflect.Chan:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface, reflect.Func:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// contains reports whether the collection contains the element, or returns an error
+// if the collection's type does not support containment
+func contains(collection interface{}, element interface{}) (bool, error) {
+	if collection == nil {
+		return false, nil
+	}
+	v := reflect.ValueOf(collection)
+	switch v.Kind() {
+	case reflect.String:
+		substring, ok := element.(string)
+		if !ok {
+			return false, fmt.Errorf("a string can only contain a string, but got %T", element)
+		}
+		return strings.Contains(v.String(), substring), nil
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if reflect.DeepEqual(v.Index(i).Interface(), element) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			if reflect.DeepEqual(key.Interface(), element) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("unsupported collection type %T", collection)
+}
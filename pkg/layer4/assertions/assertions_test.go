@@ -0,0 +1,130 @@
+package assertions
+
+import (
+	"testing"
+
+	"github.com/revanite-io/sci/pkg/layer4"
+)
+
+func TestAssertions(t *testing.T) {
+	tests := []struct {
+		name            string
+		assert          func() (layer4.Result, string)
+		expectedResult  layer4.Result
+		expectedMessage string
+	}{
+		{
+			name:            "Equal passes",
+			assert:          func() (layer4.Result, string) { return Equal("TLSv1.3", "TLSv1.3", "minimum TLS version") },
+			expectedResult:  layer4.Passed,
+			expectedMessage: "minimum TLS version is TLSv1.3",
+		},
+		{
+			name:            "Equal fails",
+			assert:          func() (layer4.Result, string) { return Equal("TLSv1.2", "TLSv1.3", "minimum TLS version") },
+			expectedResult:  layer4.Failed,
+			expectedMessage: "expected minimum TLS version to be TLSv1.3, but got TLSv1.2",
+		},
+		{
+			name:            "NotEqual passes",
+			assert:          func() (layer4.Result, string) { return NotEqual("private", "public-read", "bucket ACL") },
+			expectedResult:  layer4.Passed,
+			expectedMessage: "bucket ACL is not public-read",
+		},
+		{
+			name:            "NotEqual fails",
+			assert:          func() (layer4.Result, string) { return NotEqual("public-read", "public-read", "bucket ACL") },
+			expectedResult:  layer4.Failed,
+			expectedMessage: "expected bucket ACL not to be public-read",
+		},
+		{
+			name:            "NotEmpty passes",
+			assert:          func() (layer4.Result, string) { return NotEmpty([]string{"admin"}, "owners") },
+			expectedResult:  layer4.Passed,
+			expectedMessage: "owners is not empty",
+		},
+		{
+			name:            "NotEmpty fails on an empty slice",
+			assert:          func() (layer4.Result, string) { return NotEmpty([]string{}, "owners") },
+			expectedResult:  layer4.Failed,
+			expectedMessage: "expected owners not to be empty",
+		},
+		{
+			name:            "NotEmpty fails on nil",
+			assert:          func() (layer4.Result, string) { return NotEmpty(nil, "owners") },
+			expectedResult:  layer4.Failed,
+			expectedMessage: "expected owners not to be empty",
+		},
+		{
+			name:            "Contains passes for a slice",
+			assert:          func() (layer4.Result, string) { return Contains([]string{"owner", "environment"}, "owner", "tags") },
+			expectedResult:  layer4.Passed,
+			expectedMessage: "tags contains owner",
+		},
+		{
+			name:            "Contains passes for a map key",
+			assert:          func() (layer4.Result, string) { return Contains(map[string]string{"owner": "team"}, "owner", "tags") },
+			expectedResult:  layer4.Passed,
+			expectedMessage: "tags contains owner",
+		},
+		{
+			name:            "Contains passes for a substring",
+			assert:          func() (layer4.Result, string) { return Contains("arn:aws:kms:key", "kms", "encryption key") },
+			expectedResult:  layer4.Passed,
+			expectedMessage: "encryption key contains kms",
+		},
+		{
+			name:            "Contains fails",
+			assert:          func() (layer4.Result, string) { return Contains([]string{"environment"}, "owner", "tags") },
+			expectedResult:  layer4.Failed,
+			expectedMessage: "expected tags to contain owner",
+		},
+		{
+			name:            "Contains is Unknown for an unsupported type",
+			assert:          func() (layer4.Result, string) { return Contains(42, "owner", "tags") },
+			expectedResult:  layer4.Unknown,
+			expectedMessage: "could not check whether tags contains owner: unsupported collection type int",
+		},
+		{
+			name:            "True passes",
+			assert:          func() (layer4.Result, string) { return True(true, "versioning enabled") },
+			expectedResult:  layer4.Passed,
+			expectedMessage: "versioning enabled is true",
+		},
+		{
+			name:            "True fails",
+			assert:          func() (layer4.Result, string) { return True(false, "versioning enabled") },
+			expectedResult:  layer4.Failed,
+			expectedMessage: "expected versioning enabled to be true",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, message := test.assert()
+			if result != test.expectedResult {
+				t.Errorf("Expected %s, but got %s", test.expectedResult, result)
+			}
+			if message != test.expectedMessage {
+				t.Errorf("Expected message %q, but got %q", test.expectedMessage, message)
+			}
+		})
+	}
+}
+
+func TestAssertionsInStep(t *testing.T) {
+	step := func(payload interface{}, _ map[string]*layer4.Change) (layer4.Result, string) {
+		return Equal(payload.(map[string]string)["encryption"], "enabled", "encryption")
+	}
+	a := &layer4.Assessment{
+		Requirement_Id: "encryption",
+		Description:    "encryption is enabled",
+		Applicability:  []string{"test-applicability"},
+		Steps:          []layer4.AssessmentStep{step},
+	}
+	if result := a.Run(map[string]string{"encryption": "disabled"}, false); result != layer4.Failed {
+		t.Errorf("Expected the step to fail, but got %s", result)
+	}
+	if a.Message != "expected encryption to be enabled, but got disabled" {
+		t.Errorf("Unexpected message %q", a.Message)
+	}
+}
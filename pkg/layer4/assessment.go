@@ -1,39 +1,184 @@
 package layer4
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
-	"runtime"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 )
 
 // TestResult is a struct that contains the results of a single step within a testSet
 type Assessment struct {
-	Requirement_Id string             // Requirement_ID is the unique identifier for the requirement being tested
-	Applicability  []string           // Applicability is a slice of identifier strings to determine when this test is applicable
-	Description    string             // Description is a human-readable description of the test
-	Result         Result             // Passed is true if the test passed
-	Message        string             // Message is the human-readable result of the test
-	Steps          []AssessmentStep   // Steps is a slice of steps that were executed during the test
-	Steps_Executed int                // Steps_Executed is the number of steps that were executed during the test
-	Run_Duration   string             // Run_Duration is the time it took to run the test
-	Value          interface{}        // Value is the object that was returned during the test
-	Changes        map[string]*Change // Changes is a slice of changes that were made during the test
+	Requirement_Id        string             `json:"requirement-id" yaml:"requirement-id"`               // Requirement_ID is the unique identifier for the requirement being tested
+	Applicability         []string           `json:"applicability" yaml:"applicability"`                 // Applicability is a slice of identifier strings to determine when this test is applicable; UniversalApplicability applies it to every target
+	Description           string             `json:"description" yaml:"description"`                     // Description is a human-readable description of the test
+	Result                Result             `json:"result" yaml:"result"`                               // Passed is true if the test passed
+	Message               string             `json:"message" yaml:"message"`                             // Message is the human-readable result of the test
+	Steps                 []AssessmentStep   `json:"steps" yaml:"steps"`                                 // Steps is a slice of steps that were executed during the test
+	Context_Steps         []ContextStep      `json:"context-steps" yaml:"context-steps"`                 // Context_Steps is a slice of context-aware steps, executed after Steps
+	Steps_Executed        int                `json:"steps-executed" yaml:"steps-executed"`               // Steps_Executed is the number of steps that were executed during the test
+	Run_Duration          string             `json:"run-duration" yaml:"run-duration"`                   // Run_Duration is the time it took to run the test
+	Value                 interface{}        `json:"value" yaml:"value"`                                 // Value is the object that was returned during the test; the last non-nil StepResult.Data from a context step wins, and later steps may read it with StepValue
+	Changes               map[string]*Change `json:"changes" yaml:"changes"`                             // Changes is a slice of changes that were made during the test
+	Matched_Applicability []string           `json:"matched-applicability" yaml:"matched-applicability"` // Matched_Applicability is the subset of Applicability that matched the target when the test was evaluated
+	Review_Reason         ReviewReason       `json:"review-reason" yaml:"review-reason"`                 // Review_Reason categorizes why the test needs review, if a step provided one
+	Depends_On            []string           `json:"depends-on" yaml:"depends-on"`                       // Depends_On is a slice of requirement IDs that must pass before this test is run
+	Max_Steps             int                `json:"max-steps" yaml:"max-steps"`                         // Max_Steps is the maximum number of steps to execute before halting as Unknown; zero means unlimited
+	Evidence              []Evidence         `json:"evidence" yaml:"evidence"`                           // Evidence is a slice of artifacts supporting the result of the test
+	Labels                map[string]string  `json:"labels" yaml:"labels"`                               // Labels is arbitrary key/value metadata used for filtering and grouping tests
+	Retry_Policy          *RetryPolicy       `json:"retry-policy" yaml:"retry-policy"`                   // Retry_Policy optionally retries steps that return Failed; only the final attempt counts toward the Result
+	Retries               int                `json:"retries" yaml:"retries"`                             // Retries is the number of times a step was retried during the test
+	Retry_Messages        []string           `json:"retry-messages" yaml:"retry-messages"`               // Retry_Messages is the message from each failed attempt that was retried
+	Sub_Assessments       []*Assessment      `json:"sub-assessments" yaml:"sub-assessments"`             // Sub_Assessments are child tests run after Steps, with their results folded into this test's Result
+	Output                string             `json:"output" yaml:"output"`                               // Output is the raw output that context steps wrote to StepOutput during the test
+	Halted                bool               `json:"halted" yaml:"halted"`                               // Halted is true if the test stopped before running all of its steps and sub-assessments, such as after a failure
+	Require_Target_Data   bool               `json:"require-target-data" yaml:"require-target-data"`     // Require_Target_Data halts the test as Unknown without running any steps if the target data is nil
+	Remediation           string             `json:"remediation" yaml:"remediation"`                     // Remediation is the recommended remediation for this test, taking precedence over the control's Remediation_Guide
+	Halt_On_Unknown       bool               `json:"halt-on-unknown" yaml:"halt-on-unknown"`             // Halt_On_Unknown stops the test when a step returns Unknown, in addition to the default of halting on Failed
+	Assessment_Timeout    time.Duration      `json:"assessment-timeout" yaml:"assessment-timeout"`       // Assessment_Timeout is the wall-clock budget for the whole test, after which remaining steps are skipped as Unknown; zero means unlimited
+	Steps_Total           int                `json:"steps-total" yaml:"steps-total"`                     // Steps_Total is the number of steps the test had when it was run, so reports can show how many of them were executed
+	Rerun_Policy          RerunPolicy        `json:"rerun-policy" yaml:"rerun-policy"`                   // Rerun_Policy determines what happens when the test is run again without calling Reset; defaults to RerunAllowed
+	NotApplicable_To      []string           `json:"not-applicable-to" yaml:"not-applicable-to"`         // NotApplicable_To is a slice of identifier strings that exclude the test from a target, taking precedence over any matching Applicability
+	Step_Error_Messages   []string           `json:"step-error-messages" yaml:"step-error-messages"`     // Step_Error_Messages is the message of each error in Step_Errors, which is what gets serialized
+	Interrupted           bool               `json:"interrupted" yaml:"interrupted"`                     // Interrupted is true if the test was cut short by cancellation or its Assessment_Timeout, so Resume runs it again
+
+	Applicability_Matcher ApplicabilityMatcher `json:"-" yaml:"-"` // Applicability_Matcher determines whether the test applies to a target; defaults to ExactMatcher
+	Clock                 Clock                `json:"-" yaml:"-"` // Clock provides the time used to measure Run_Duration; defaults to the system clock
+	Halt_Predicate        HaltPredicate        `json:"-" yaml:"-"` // Halt_Predicate optionally decides which step results stop the test, replacing the Failed and Halt_On_Unknown checks
+	Progress_Callback     StepProgressFunc     `json:"-" yaml:"-"` // Progress_Callback is optionally called after each step with the number of steps done and the total
+	Step_Errors           []error              `json:"-" yaml:"-"` // Step_Errors is the StepResult.Error returned by each step that reported one, wrapped with the step's number
+
+	ran bool // ran is true once the test has completed a run since it was created or last Reset, so that Rerun_Policy can be applied
 }
 
 // AssessmentStep is a function type that inspects the provided targetData and returns a Result with a message.
 // The message may be an error string or other descriptive text.
 type AssessmentStep func(payload interface{}, c map[string]*Change) (Result, string)
 
+// StepProgressFunc receives the number of steps completed so far and the total number of steps in an assessment
+type StepProgressFunc func(done, total int)
+
+// HaltPredicate reports whether a run should stop after producing the provided result
+type HaltPredicate func(result Result) bool
+
+// ErrAlreadyRun is returned when an assessment with a Rerun_Policy of RerunError is run again without calling Reset
+var ErrAlreadyRun = errors.New("assessment has already run; call Reset before running it again")
+
+// ErrMisconfigured matches the error returned when an assessment fails validation, such as when a required
+// field is missing, as opposed to an assessment that is correctly configured but does not apply to the target
+var ErrMisconfigured = errors.New("assessment is misconfigured")
+
+// misconfiguredError marks a validation error so that it matches ErrMisconfigured, without changing its message
+type misconfiguredError struct {
+	err error
+}
+
+func (e misconfiguredError) Error() string {
+	return e.err.Error()
+}
+
+func (e misconfiguredError) Unwrap() error {
+	return e.err
+}
+
+func (e misconfiguredError) Is(target error) bool {
+	return target == ErrMisconfigured
+}
+
+// RerunPolicy determines what happens when an Assessment that has already run is run again without calling Reset
+type RerunPolicy int
+
+const (
+	RerunAllowed RerunPolicy = iota // RerunAllowed runs the assessment again, accumulating onto the previous results
+	RerunSkip                       // RerunSkip leaves the previous results in place and returns the previous Result without running any steps
+	RerunError                      // RerunError behaves like RerunSkip, but the run reports ErrAlreadyRun
+)
+
+var rerunPolicyToString = map[RerunPolicy]string{
+	RerunAllowed: "Allowed",
+	RerunSkip:    "Skip",
+	RerunError:   "Error",
+}
+
+func (p RerunPolicy) String() string {
+	return rerunPolicyToString[p]
+}
+
+// UnmarshalJSON parses a RerunPolicy from its string representation in JSON
+func (p *RerunPolicy) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	for policy, str := range rerunPolicyToString {
+		if str == s {
+			*p = policy
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown rerun policy: %q", s)
+}
+
+// MarshalYAML ensures that RerunPolicy is serialized as a string in YAML
+func (p RerunPolicy) MarshalYAML() (interface{}, error) {
+	return p.String(), nil
+}
+
+// MarshalJSON ensures that RerunPolicy is serialized as a string in JSON
+func (p RerunPolicy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// ReviewReason categorizes why an assessment needs review
+type ReviewReason string
+
+const (
+	ManualVerification ReviewReason = "Manual Verification" // ManualVerification means a human must verify the requirement
+	AmbiguousResult    ReviewReason = "Ambiguous Result"    // AmbiguousResult means the automated result could not be interpreted with confidence
+	ToolLimitation     ReviewReason = "Tool Limitation"     // ToolLimitation means the tooling is unable to fully assess the requirement
+)
+
+const reviewReasonPrefix = "[review:"
+
+// NeedsReviewBecause returns the values an AssessmentStep should return to signal NeedsReview with a ReviewReason.
+// Because AssessmentStep can only return a Result and a message, the reason is encoded as a "[review:<reason>] " prefix
+// on the message; the prefix is removed and the reason is recorded on the Assessment's Review_Reason when the step runs.
+func NeedsReviewBecause(reason ReviewReason, message string) (Result, string) {
+	return NeedsReview, fmt.Sprintf("%s%s] %s", reviewReasonPrefix, reason, message)
+}
+
+// ManualStep returns an AssessmentStep for a check that cannot be automated.
+// The step always records NeedsReview with the ManualVerification reason and the provided prompt as its message,
+// and does not halt the run, so that any remaining automated steps are still executed.
+func ManualStep(prompt string) AssessmentStep {
+	return func(_ interface{}, _ map[string]*Change) (Result, string) {
+		return NeedsReviewBecause(ManualVerification, prompt)
+	}
+}
+
+// parseReviewReason extracts a ReviewReason encoded by NeedsReviewBecause from a step message
+func parseReviewReason(message string) (reason ReviewReason, trimmed string, ok bool) {
+	if !strings.HasPrefix(message, reviewReasonPrefix) {
+		return "", message, false
+	}
+	end := strings.Index(message, "] ")
+	if end == -1 {
+		return "", message, false
+	}
+	return ReviewReason(message[len(reviewReasonPrefix):end]), message[end+2:], true
+}
+
 func (as AssessmentStep) String() string {
-	// Get the function pointer correctly
-	fn := runtime.FuncForPC(reflect.ValueOf(as).Pointer())
-	if fn == nil {
-		return "<unknown function>"
+	if name, ok := decodedStepName(as.identity()); ok {
+		return name
 	}
-	return fn.Name()
+	return functionName(as)
 }
 
 func (as AssessmentStep) MarshalJSON() ([]byte, error) {
@@ -44,6 +189,16 @@ func (as AssessmentStep) MarshalYAML() (interface{}, error) {
 	return as.String(), nil
 }
 
+// UnmarshalJSON decodes a step serialized by its function name, as described by ContextStep.UnmarshalJSON
+func (as *AssessmentStep) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	*as = decodedAssessmentStep(name)
+	return nil
+}
+
 // NewAssessment creates a new Assessment object and returns a pointer to it.
 // The function demands a requirementId, description, applicability, and steps.
 func NewAssessment(requirementId string, description string, applicability []string, steps []AssessmentStep) (*Assessment, error) {
@@ -63,36 +218,390 @@ func (a *Assessment) AddStep(step AssessmentStep) {
 	a.Steps = append(a.Steps, step)
 }
 
+// AddSteps queues several new steps in the Assessment, in order
+func (a *Assessment) AddSteps(steps ...AssessmentStep) {
+	a.Steps = append(a.Steps, steps...)
+}
+
+// DedupeSteps removes repeated Steps and Context_Steps, keeping the first occurrence of each in order,
+// and returns the number of steps removed. Steps are compared by identity, so the same function value
+// added twice is removed, while separate closures created from one function literal are kept.
+func (a *Assessment) DedupeSteps() (removed int) {
+	seen := make(map[uintptr]bool)
+	var steps []AssessmentStep
+	for _, step := range a.Steps {
+		if seen[step.identity()] {
+			removed++
+			continue
+		}
+		seen[step.identity()] = true
+		steps = append(steps, step)
+	}
+	var contextSteps []ContextStep
+	for _, step := range a.Context_Steps {
+		if seen[step.identity()] {
+			removed++
+			continue
+		}
+		seen[step.identity()] = true
+		contextSteps = append(contextSteps, step)
+	}
+	a.Steps, a.Context_Steps = steps, contextSteps
+	return
+}
+
+// AddContextStep queues a new context-aware step in the Assessment
+func (a *Assessment) AddContextStep(step ContextStep) {
+	a.Context_Steps = append(a.Context_Steps, step)
+}
+
 func (a *Assessment) runStep(targetData interface{}, step AssessmentStep) Result {
+	return a.runContextStep(context.Background(), targetData, step.withContext())
+}
+
+func (a *Assessment) runContextStep(ctx context.Context, targetData interface{}, step ContextStep) Result {
 	a.Steps_Executed++
-	result, message := step(targetData, a.Changes)
-	a.Result = UpdateAggregateResult(a.Result, result)
+	stepResult := step(ctx, targetData, a.Changes)
+	for attempt := 1; stepResult.Result == Failed && a.Retry_Policy != nil && attempt < a.Retry_Policy.Attempts; attempt++ {
+		if !a.Retry_Policy.wait(ctx, attempt) {
+			break
+		}
+		a.Retries++
+		a.Retry_Messages = append(a.Retry_Messages, stepResult.Message)
+		stepResult = step(ctx, targetData, a.Changes)
+	}
+	a.Evidence = append(a.Evidence, stepResult.Evidence...)
+	if stepResult.Data != nil {
+		a.Value = stepResult.Data
+	}
+	if stepResult.Error != nil {
+		err := fmt.Errorf("step %d: %w", a.Steps_Executed, stepResult.Error)
+		a.Step_Errors = append(a.Step_Errors, err)
+		a.Step_Error_Messages = append(a.Step_Error_Messages, err.Error())
+	}
+	result, message := stepResult.Result, stepResult.Message
+	if message == "" && stepResult.Error != nil {
+		message = stepResult.Error.Error()
+	}
+	if result == NeedsReview {
+		if reason, trimmed, ok := parseReviewReason(message); ok {
+			a.Review_Reason = reason
+			message = trimmed
+		}
+	}
+	if result == NotApplicable && (a.Result == NotRun || a.Result == Passed) {
+		// a step may determine at runtime that the assessment does not apply, which overrides any earlier passing steps
+		// but never a result that still needs attention, such as Failed
+		a.Result = NotApplicable
+	} else {
+		a.Result = UpdateAggregateResult(a.Result, result)
+	}
 	a.Message = message
 	return result
 }
 
-// Run will execute all steps, halting if any step does not return layer4.Passed
+// allSteps returns Steps followed by Context_Steps, adapted to a single signature
+func (a *Assessment) allSteps() []ContextStep {
+	steps := make([]ContextStep, 0, len(a.Steps)+len(a.Context_Steps))
+	for _, step := range a.Steps {
+		steps = append(steps, step.withContext())
+	}
+	return append(steps, a.Context_Steps...)
+}
+
+// Run will execute all steps, including context steps, halting if any step does not return layer4.Passed
+// Any Sub_Assessments are then run in order, each folding its Result into this one and halting if a child fails
+// A step may return layer4.NotApplicable to signal that the assessment does not apply to the target after all,
+// which halts the run and marks the assessment NotApplicable rather than Failed
 // `targetData` is the data that the assessment will be run against
 // `changesAllowed` is a boolean that determines whether changes will be applied
 func (a *Assessment) Run(targetData interface{}, changesAllowed bool) Result {
-	startTime := time.Now()
+	return a.RunWithContext(context.Background(), targetData, changesAllowed)
+}
+
+// RunWithContext behaves like Run, passing ctx to each context step and checking it before each step.
+// If ctx is cancelled before all steps have run, the remaining steps are skipped and the Result is Unknown.
+// If the assessment has already run, the Rerun_Policy determines whether it runs again; use TryRunWithContext
+// to receive ErrAlreadyRun under RerunError.
+func (a *Assessment) RunWithContext(ctx context.Context, targetData interface{}, changesAllowed bool) Result {
+	result, _ := a.run(ctx, targetData, changesAllowed)
+	return result
+}
+
+// TryRun behaves like Run, but also returns an error if the assessment could not be run as intended,
+// such as when it fails its precheck, or ErrAlreadyRun when it has already run under a Rerun_Policy of RerunError
+func (a *Assessment) TryRun(targetData interface{}, changesAllowed bool) (Result, error) {
+	return a.TryRunWithContext(context.Background(), targetData, changesAllowed)
+}
+
+// TryRunWithContext behaves like RunWithContext, returning an error as described by TryRun
+func (a *Assessment) TryRunWithContext(ctx context.Context, targetData interface{}, changesAllowed bool) (Result, error) {
+	return a.run(ctx, targetData, changesAllowed)
+}
+
+// run executes the assessment as described by RunWithContext, additionally returning
+// the precheck error if the assessment could not be run
+func (a *Assessment) run(ctx context.Context, targetData interface{}, changesAllowed bool) (Result, error) {
+	return a.runWithSettings(ctx, targetData, changesAllowed, a.settings())
+}
+
+// runWithSettings executes the assessment as described by run, using the provided settings in place of its own
+// so that a ControlEvaluation can apply its settings without modifying the assessment
+func (a *Assessment) runWithSettings(ctx context.Context, targetData interface{}, changesAllowed bool, settings runSettings) (Result, error) {
+	if a.ran {
+		switch a.Rerun_Policy {
+		case RerunSkip:
+			return a.Result, nil
+		case RerunError:
+			return a.Result, ErrAlreadyRun
+		}
+	}
+	clock := a.clock()
+	startTime := clock.Now()
 	err := a.precheck()
 	if err != nil {
 		a.Result = Unknown
-		return a.Result
+		return a.Result, err
+	}
+	if settings.requireTargetData && isNil(targetData) {
+		a.Result = Unknown
+		a.Message = "target data is required, but got nil"
+		return a.Result, errors.New(a.Message)
 	}
 	for _, change := range a.Changes {
+		change.clock = clock
 		if !changesAllowed {
 			change.Disallow()
 		}
 	}
-	for _, step := range a.Steps {
-		if a.runStep(targetData, step) == Failed {
-			return Failed
+	ctx = a.withMetadata(ctx)
+	// runCtx carries the Assessment_Timeout as a deadline, so that a step that respects its context
+	// is stopped partway rather than only being checked between steps
+	runCtx := ctx
+	if a.Assessment_Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, a.Assessment_Timeout)
+		defer cancel()
+	}
+	timedOut := func() bool {
+		if a.Assessment_Timeout <= 0 || (runCtx.Err() == nil && clock.Now().Sub(startTime) < a.Assessment_Timeout) {
+			return false
 		}
+		a.Result = UpdateAggregateResult(a.Result, Unknown)
+		a.Message = fmt.Sprintf("halted after exceeding the assessment timeout of %s", a.Assessment_Timeout)
+		a.Interrupted = true
+		return true
+	}
+	stepCtx := a.withValue(a.withOutput(runCtx))
+	steps := a.allSteps()
+	a.Steps_Total = len(steps)
+	ran := 0
+	for i, step := range steps {
+		if err := ctx.Err(); err != nil {
+			a.Result = UpdateAggregateResult(a.Result, Unknown)
+			a.Message = fmt.Sprintf("halted after cancellation: %v", err)
+			a.Interrupted = true
+			break
+		}
+		if timedOut() {
+			break
+		}
+		if a.Max_Steps > 0 && i >= a.Max_Steps {
+			a.Result = UpdateAggregateResult(a.Result, Unknown)
+			a.Message = fmt.Sprintf("halted after reaching the maximum of %d steps", a.Max_Steps)
+			break
+		}
+		ran++
+		result := a.runContextStep(stepCtx, targetData, step)
+		if a.Progress_Callback != nil {
+			a.Progress_Callback(ran, len(steps))
+		}
+		if ctx.Err() == nil && runCtx.Err() != nil && timedOut() {
+			break
+		}
+		if result == NotApplicable || settings.shouldHalt(result) {
+			break
+		}
+	}
+	a.Halted = ran < len(steps) || a.Interrupted
+	var errs []error
+	for _, child := range a.Sub_Assessments {
+		if a.Result == NotApplicable || settings.shouldHalt(a.Result) || timedOut() {
+			a.Halted = true
+			break
+		}
+		result, err := child.run(runCtx, targetData, changesAllowed)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sub-assessment %s could not be run: %w", child.Requirement_Id, err))
+		}
+		a.Result = UpdateAggregateResult(a.Result, result)
+		a.Message = child.Message
+		a.Interrupted = a.Interrupted || child.Interrupted
+	}
+	a.Run_Duration = clock.Now().Sub(startTime).String()
+	a.ran = true
+	return a.Result, errors.Join(errs...)
+}
+
+// runSettings are the settings an Assessment runs with, which a ControlEvaluation may supplement with its own
+type runSettings struct {
+	matcher           ApplicabilityMatcher
+	requireTargetData bool
+	haltOnUnknown     bool
+	haltPredicate     HaltPredicate
+}
+
+// settings returns the settings the Assessment runs with on its own
+func (a *Assessment) settings() runSettings {
+	return runSettings{
+		matcher:           a.Applicability_Matcher,
+		requireTargetData: a.Require_Target_Data,
+		haltOnUnknown:     a.Halt_On_Unknown,
+		haltPredicate:     a.Halt_Predicate,
+	}
+}
+
+// shouldHalt reports whether the result should stop the Assessment, using the halt predicate if one is set.
+// By default only Failed halts, along with Unknown if Halt_On_Unknown is set.
+func (s runSettings) shouldHalt(result Result) bool {
+	if s.haltPredicate != nil {
+		return s.haltPredicate(result)
+	}
+	return result == Failed || (s.haltOnUnknown && result == Unknown)
+}
+
+// String returns a compact summary of the Assessment for logs, such as "CCC.C01.TR01: Failed (bucket is public)"
+func (a *Assessment) String() string {
+	if a.Message == "" {
+		return fmt.Sprintf("%s: %s", a.Requirement_Id, a.Result)
+	}
+	return fmt.Sprintf("%s: %s (%s)", a.Requirement_Id, a.Result, a.Message)
+}
+
+// Err returns the errors reported by the Assessment's steps and those of its Sub_Assessments joined together,
+// or nil if none were reported, so that callers can use errors.Is and errors.As against the errors steps returned
+func (a *Assessment) Err() error {
+	errs := append([]error(nil), a.Step_Errors...)
+	for _, child := range a.Sub_Assessments {
+		if err := child.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("sub-assessment %s: %w", child.Requirement_Id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// clock returns the Assessment's Clock, or the system clock if none is set
+func (a *Assessment) clock() Clock {
+	if a.Clock == nil {
+		return defaultClock
+	}
+	return a.Clock
+}
+
+// isNil returns true if the value is nil or is a typed nil, such as a nil pointer
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
+// SetRemediation sets the recommended remediation for the Assessment
+func (a *Assessment) SetRemediation(remediation string) {
+	a.Remediation = remediation
+}
+
+// SetLabel sets a key/value label on the Assessment
+func (a *Assessment) SetLabel(key, value string) {
+	if a.Labels == nil {
+		a.Labels = make(map[string]string)
+	}
+	a.Labels[key] = value
+}
+
+// Clone returns an independent copy of the Assessment with its execution state reset to NotRun.
+// Changes are copied into a fresh map and reset to their pending state, while the steps themselves are shared
+// because steps are expected to be stateless.
+func (a *Assessment) Clone() *Assessment {
+	clone := a.copy()
+	clone.resetChanges()
+	clone.Reset()
+	return clone
+}
+
+// copy returns an independent copy of the Assessment that keeps its results, as used for reports.
+// Its slices, maps, Changes, and Sub_Assessments are copied, while the Value, the Target_Object of each change,
+// and the steps themselves are shared.
+func (a *Assessment) copy() *Assessment {
+	copied := *a
+	copied.Applicability = slices.Clone(a.Applicability)
+	copied.Steps = slices.Clone(a.Steps)
+	copied.Context_Steps = slices.Clone(a.Context_Steps)
+	copied.Matched_Applicability = slices.Clone(a.Matched_Applicability)
+	copied.Depends_On = slices.Clone(a.Depends_On)
+	copied.Evidence = slices.Clone(a.Evidence)
+	copied.Labels = maps.Clone(a.Labels)
+	copied.Retry_Messages = slices.Clone(a.Retry_Messages)
+	copied.NotApplicable_To = slices.Clone(a.NotApplicable_To)
+	copied.Step_Errors = slices.Clone(a.Step_Errors)
+	copied.Step_Error_Messages = slices.Clone(a.Step_Error_Messages)
+	if a.Retry_Policy != nil {
+		policy := *a.Retry_Policy
+		copied.Retry_Policy = &policy
+	}
+	if a.Changes != nil {
+		copied.Changes = make(map[string]*Change, len(a.Changes))
+		for name, change := range a.Changes {
+			changeCopy := *change
+			copied.Changes[name] = &changeCopy
+		}
+	}
+	copied.Sub_Assessments = nil
+	for _, child := range a.Sub_Assessments {
+		copied.Sub_Assessments = append(copied.Sub_Assessments, child.copy())
+	}
+	return &copied
+}
+
+// resetChanges returns the changes of a copied Assessment and its Sub_Assessments to their pending state
+func (a *Assessment) resetChanges() {
+	for name, change := range a.Changes {
+		a.Changes[name] = change.clone()
+	}
+	for _, child := range a.Sub_Assessments {
+		child.resetChanges()
+	}
+}
+
+// Reset returns the Assessment and its Sub_Assessments to the NotRun state, clearing the results of any previous run
+// so that it may be run again under any Rerun_Policy. Changes keep their state, so any applied changes should be
+// reverted before the Assessment is run again.
+func (a *Assessment) Reset() {
+	a.Result = NotRun
+	a.Message = ""
+	a.Steps_Executed = 0
+	a.Steps_Total = 0
+	a.Run_Duration = ""
+	a.Value = nil
+	a.Matched_Applicability = nil
+	a.Review_Reason = ""
+	a.Evidence = nil
+	a.Retries = 0
+	a.Retry_Messages = nil
+	a.Step_Errors = nil
+	a.Step_Error_Messages = nil
+	a.Output = ""
+	a.Halted = false
+	a.Interrupted = false
+	a.ran = false
+	for _, child := range a.Sub_Assessments {
+		child.Reset()
 	}
-	a.Run_Duration = time.Since(startTime).String()
-	return a.Result
 }
 
 // NewChange creates a new Change object and adds it to the Assessment
@@ -111,6 +620,17 @@ func (a *Assessment) NewChange(changeName, targetName, description string, targe
 	return a.Changes[changeName]
 }
 
+// NewReadOnlyChange creates a Change with no-op apply and revert functions and adds it to the Assessment.
+// This allows read-only probes to be recorded alongside real changes without defining empty functions.
+func (a *Assessment) NewReadOnlyChange(changeName, targetName, description string, targetObject interface{}) *Change {
+	change := a.NewChange(changeName, targetName, description, targetObject,
+		func() (interface{}, error) { return nil, nil },
+		func() error { return nil },
+	)
+	change.readOnly = true
+	return change
+}
+
 func (a *Assessment) RevertChanges() (corrupted bool) {
 	for _, change := range a.Changes {
 		if !corrupted && (change.Applied || change.Error != nil) {
@@ -122,18 +642,121 @@ func (a *Assessment) RevertChanges() (corrupted bool) {
 			}
 		}
 	}
+	for _, child := range a.Sub_Assessments {
+		if child.RevertChanges() {
+			corrupted = true
+		}
+	}
+	return
+}
+
+// ForceRevertAll calls ForceRevert on every change in the Assessment, regardless of whether it was applied,
+// returning true if any change could not be reverted. Each revert function must be idempotent.
+func (a *Assessment) ForceRevertAll() (corrupted bool) {
+	for _, change := range a.Changes {
+		change.ForceRevert()
+		if !change.Reverted {
+			corrupted = true
+		}
+	}
+	for _, child := range a.Sub_Assessments {
+		if child.ForceRevertAll() {
+			corrupted = true
+		}
+	}
 	return
 }
 
+// allChanges returns the changes of the Assessment and its Sub_Assessments
+func (a *Assessment) allChanges() []*Change {
+	changes := make([]*Change, 0, len(a.Changes))
+	for _, change := range a.Changes {
+		changes = append(changes, change)
+	}
+	for _, child := range a.Sub_Assessments {
+		changes = append(changes, child.allChanges()...)
+	}
+	return changes
+}
+
+// changeNames returns the names of the Assessment's changes in sorted order
+func (a *Assessment) changeNames() []string {
+	names := make([]string, 0, len(a.Changes))
+	for name := range a.Changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// revertErrors returns an error for each change left in an error state, ordered by change name
+func (a *Assessment) revertErrors() (errs []error) {
+	for _, name := range a.changeNames() {
+		change := a.Changes[name]
+		if change.Error != nil {
+			errs = append(errs, fmt.Errorf("change %s on target %s could not be reverted: %w", name, change.Target_Name, change.Error))
+		}
+	}
+	for _, child := range a.Sub_Assessments {
+		errs = append(errs, child.revertErrors()...)
+	}
+	return
+}
+
+// RevertExpiredChanges calls CheckExpired on each change in the Assessment,
+// returning the number of changes that were reverted because their Rollback_Window elapsed.
+// The concurrency caveats of Change.CheckExpired apply.
+func (a *Assessment) RevertExpiredChanges() (reverted int) {
+	for _, change := range a.Changes {
+		if change.CheckExpired() {
+			reverted++
+		}
+	}
+	for _, child := range a.Sub_Assessments {
+		reverted += child.RevertExpiredChanges()
+	}
+	return
+}
+
+// precheck validates the assessment, recording any validation error as an Unknown result
 func (a *Assessment) precheck() error {
-	if a.Requirement_Id == "" || a.Description == "" || a.Applicability == nil || a.Steps == nil || len(a.Applicability) == 0 || len(a.Steps) == 0 {
-		message := fmt.Sprintf(
+	err := a.validate()
+	if err != nil {
+		a.Result = Unknown
+		a.Message = err.Error()
+	}
+	return err
+}
+
+// IsMisconfigured reports whether the assessment fails validation and so cannot be run.
+// An assessment that does not apply to a target is not misconfigured; it is marked NotApplicable without an error.
+func (a *Assessment) IsMisconfigured() bool {
+	return a.validate() != nil
+}
+
+// validate verifies that the assessment's required fields have values, without modifying the assessment.
+// Any error it returns matches ErrMisconfigured.
+func (a *Assessment) validate() error {
+	if err := a.validateFields(); err != nil {
+		return misconfiguredError{err: err}
+	}
+	return nil
+}
+
+// validateFields performs the checks described by validate
+func (a *Assessment) validateFields() error {
+	stepCount := len(a.Steps) + len(a.Context_Steps)
+	if a.Requirement_Id == "" || a.Description == "" || a.Applicability == nil || len(a.Applicability) == 0 || (stepCount == 0 && len(a.Sub_Assessments) == 0) {
+		return fmt.Errorf(
 			"expected all Assessment fields to have a value, but got: requirementId=len(%v), description=len=(%v), applicability=len(%v), steps=len(%v)",
-			len(a.Requirement_Id), len(a.Description), len(a.Applicability), len(a.Steps),
+			len(a.Requirement_Id), len(a.Description), len(a.Applicability), stepCount,
 		)
-		a.Result = Unknown
-		a.Message = message
-		return errors.New(message)
+	}
+	if err := validateApplicability(a.Applicability); err != nil {
+		return err
+	}
+	if err := validateApplicability(a.NotApplicable_To); err != nil {
+		return err
 	}
 
 	return nil
@@ -1,7 +1,13 @@
 package layer4
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 )
 
 var assessmentsTestData = []struct {
@@ -280,3 +286,776 @@ func TestNewAssessment(t *testing.T) {
 		})
 	}
 }
+
+// TestReviewReason ensures that a reason provided via NeedsReviewBecause is recorded on the Assessment
+func TestReviewReason(t *testing.T) {
+	tests := []struct {
+		testName        string
+		step            AssessmentStep
+		expectedReason  ReviewReason
+		expectedMessage string
+	}{
+		{
+			testName: "Step with review reason",
+			step: func(interface{}, map[string]*Change) (Result, string) {
+				return NeedsReviewBecause(ToolLimitation, "unable to read the config")
+			},
+			expectedReason:  ToolLimitation,
+			expectedMessage: "unable to read the config",
+		},
+		{
+			testName: "Step without review reason",
+			step: func(interface{}, map[string]*Change) (Result, string) {
+				return NeedsReview, "[not a reason] please check"
+			},
+			expectedMessage: "[not a reason] please check",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			a := Assessment{}
+			result := a.runStep(nil, test.step)
+			if result != NeedsReview {
+				t.Errorf("expected %s, got %s", NeedsReview, result)
+			}
+			if a.Review_Reason != test.expectedReason {
+				t.Errorf("expected review reason %q, got %q", test.expectedReason, a.Review_Reason)
+			}
+			if a.Message != test.expectedMessage {
+				t.Errorf("expected message %q, got %q", test.expectedMessage, a.Message)
+			}
+		})
+	}
+}
+
+// TestClone ensures that mutating a cloned Assessment does not affect the original
+func TestClone(t *testing.T) {
+	original := &Assessment{
+		Requirement_Id: "original",
+		Description:    "original assessment",
+		Applicability:  []string{"test-applicability"},
+		Steps:          []AssessmentStep{passingAssessmentStep},
+	}
+	original.NewChange("change", "target", "description", nil, goodApplyFunc, goodRevertFunc)
+	original.Run(nil, true)
+	original.Changes["change"].Apply()
+
+	clone := original.Clone()
+	if clone.Result != NotRun || clone.Steps_Executed != 0 || clone.Run_Duration != "" {
+		t.Errorf("expected clone to be reset, got result=%s, steps executed=%d", clone.Result, clone.Steps_Executed)
+	}
+	if clone.Changes["change"] == original.Changes["change"] {
+		t.Fatal("expected clone to have its own Change objects")
+	}
+	if clone.Changes["change"].Applied {
+		t.Error("expected cloned change to be reset to pending")
+	}
+
+	clone.Applicability[0] = "mutated"
+	clone.Run(nil, true)
+	clone.Changes["change"].Revert()
+	if original.Applicability[0] != "test-applicability" {
+		t.Errorf("expected original applicability to be unchanged, got %s", original.Applicability[0])
+	}
+	if original.Steps_Executed != 1 {
+		t.Errorf("expected original to have executed 1 step, got %d", original.Steps_Executed)
+	}
+	if original.Changes["change"].Reverted {
+		t.Error("expected reverting the cloned change not to affect the original change")
+	}
+}
+
+// TestMaxSteps ensures that Run halts once Max_Steps steps have been executed
+func TestMaxSteps(t *testing.T) {
+	tests := []struct {
+		testName       string
+		maxSteps       int
+		expectedSteps  int
+		expectedResult Result
+	}{
+		{
+			testName:       "Unlimited",
+			maxSteps:       0,
+			expectedSteps:  4,
+			expectedResult: Passed,
+		},
+		{
+			testName:       "Limit equal to step count",
+			maxSteps:       4,
+			expectedSteps:  4,
+			expectedResult: Passed,
+		},
+		{
+			testName:       "Limit below step count",
+			maxSteps:       2,
+			expectedSteps:  2,
+			expectedResult: Unknown,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			a := &Assessment{
+				Requirement_Id: "max-steps",
+				Description:    "max steps",
+				Applicability:  testingApplicability,
+				Steps:          []AssessmentStep{passingAssessmentStep, passingAssessmentStep, passingAssessmentStep, passingAssessmentStep},
+				Max_Steps:      test.maxSteps,
+			}
+			result := a.Run(nil, false)
+			if result != test.expectedResult {
+				t.Errorf("expected %s, got %s", test.expectedResult, result)
+			}
+			if a.Steps_Executed != test.expectedSteps {
+				t.Errorf("expected %d steps to be executed, got %d", test.expectedSteps, a.Steps_Executed)
+			}
+		})
+	}
+}
+
+func TestRuntimeNotApplicable(t *testing.T) {
+	notApplicableStep := func(payload interface{}, _ map[string]*Change) (Result, string) {
+		if payload == "windows" {
+			return NotApplicable, "target is not a linux host"
+		}
+		return Passed, "target is a linux host"
+	}
+	tests := []struct {
+		name           string
+		payload        string
+		expectedResult Result
+		expectedSteps  int
+	}{
+		{name: "Applicable at runtime", payload: "linux", expectedResult: Passed, expectedSteps: 2},
+		{name: "Not applicable at runtime", payload: "windows", expectedResult: NotApplicable, expectedSteps: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := &Assessment{
+				Requirement_Id: "runtime",
+				Description:    "runtime",
+				Applicability:  testingApplicability,
+				Steps:          []AssessmentStep{notApplicableStep, passingAssessmentStep},
+			}
+			result := a.Run(test.payload, false)
+			if result != test.expectedResult {
+				t.Errorf("Expected Result to be %v, but got %v", test.expectedResult, result)
+			}
+			if a.Steps_Executed != test.expectedSteps {
+				t.Errorf("Expected %d steps to be executed, but got %d", test.expectedSteps, a.Steps_Executed)
+			}
+		})
+	}
+
+	t.Run("Control result", func(t *testing.T) {
+		c := &ControlEvaluation{Assessments: []*Assessment{
+			{Requirement_Id: "runtime", Description: "runtime", Applicability: testingApplicability, Steps: []AssessmentStep{notApplicableStep}},
+		}}
+		c.Evaluate("windows", testingApplicability, false)
+		if c.Result != NotApplicable {
+			t.Errorf("Expected the control Result to be %v, but got %v", NotApplicable, c.Result)
+		}
+	})
+
+	t.Run("After a failure", func(t *testing.T) {
+		a := &Assessment{
+			Requirement_Id: "runtime",
+			Description:    "runtime",
+			Applicability:  testingApplicability,
+			Steps:          []AssessmentStep{failingAssessmentStep, notApplicableStep},
+			Halt_Predicate: func(Result) bool { return false },
+		}
+		if result := a.Run("windows", false); result != Failed {
+			t.Errorf("Expected the earlier failure to be kept, but got %v", result)
+		}
+	})
+}
+
+func TestSubAssessments(t *testing.T) {
+	newChild := func(id string, step AssessmentStep) *Assessment {
+		return &Assessment{Requirement_Id: id, Description: id, Applicability: testingApplicability, Steps: []AssessmentStep{step}}
+	}
+	passing := newChild("passing", passingAssessmentStep)
+	passing.NewChange("change", "target", "description", nil, goodApplyFunc, goodRevertFunc).Apply()
+	failing := newChild("failing", failingAssessmentStep)
+	skipped := newChild("skipped", passingAssessmentStep)
+	parent := &Assessment{
+		Requirement_Id:  "parent",
+		Description:     "parent",
+		Applicability:   testingApplicability,
+		Sub_Assessments: []*Assessment{passing, failing, skipped},
+	}
+
+	result := parent.Run(nil, true)
+
+	if result != Failed {
+		t.Errorf("Expected the parent Result to be %v, but got %v", Failed, result)
+	}
+	if passing.Result != Passed || failing.Result != Failed {
+		t.Errorf("Expected the children to be %v and %v, but got %v and %v", Passed, Failed, passing.Result, failing.Result)
+	}
+	if skipped.Steps_Executed != 0 {
+		t.Errorf("Expected children after a failure not to run")
+	}
+	if parent.RevertChanges() {
+		t.Errorf("Expected reverting the parent not to be corrupted")
+	}
+	if !passing.Changes["change"].Reverted {
+		t.Errorf("Expected reverting the parent to revert child changes")
+	}
+}
+
+func TestHalted(t *testing.T) {
+	tests := []struct {
+		testName       string
+		steps          []AssessmentStep
+		expectedHalted bool
+	}{
+		{
+			testName:       "Completed all steps",
+			steps:          []AssessmentStep{passingAssessmentStep, passingAssessmentStep},
+			expectedHalted: false,
+		},
+		{
+			testName:       "Failed on the last step",
+			steps:          []AssessmentStep{passingAssessmentStep, failingAssessmentStep},
+			expectedHalted: false,
+		},
+		{
+			testName:       "Halted after a failed step",
+			steps:          []AssessmentStep{failingAssessmentStep, passingAssessmentStep, passingAssessmentStep},
+			expectedHalted: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			a := &Assessment{
+				Requirement_Id: "halted",
+				Description:    "halted",
+				Applicability:  testingApplicability,
+				Steps:          test.steps,
+			}
+			a.Run(nil, false)
+			if a.Halted != test.expectedHalted {
+				t.Errorf("expected Halted to be %v, got %v", test.expectedHalted, a.Halted)
+			}
+		})
+	}
+}
+
+func TestNewReadOnlyChange(t *testing.T) {
+	a := &Assessment{}
+	change := a.NewReadOnlyChange("probe", "bucket", "inspect the bucket policy", "policy")
+	if a.Changes["probe"] != change {
+		t.Fatalf("Expected the change to be added to the assessment")
+	}
+	if !change.Apply() {
+		t.Errorf("Expected the read-only change to apply")
+	}
+	if change.Target_Object != "policy" {
+		t.Errorf("Expected the Target_Object to be preserved, but got %v", change.Target_Object)
+	}
+	c := &ControlEvaluation{Assessments: []*Assessment{a}}
+	c.Cleanup()
+	if c.Corrupted_State {
+		t.Errorf("Expected Cleanup not to treat a read-only change as corrupting")
+	}
+	if !change.Reverted {
+		t.Errorf("Expected the read-only change to be reverted")
+	}
+}
+
+func TestRequireTargetData(t *testing.T) {
+	var nilPointer *struct{}
+	tests := []struct {
+		name           string
+		require        bool
+		targetData     interface{}
+		expectedResult Result
+		expectedSteps  int
+	}{
+		{name: "Nil allowed", require: false, targetData: nil, expectedResult: Passed, expectedSteps: 1},
+		{name: "Nil required", require: true, targetData: nil, expectedResult: Unknown, expectedSteps: 0},
+		{name: "Typed nil required", require: true, targetData: nilPointer, expectedResult: Unknown, expectedSteps: 0},
+		{name: "Data provided", require: true, targetData: "target", expectedResult: Passed, expectedSteps: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := &Assessment{
+				Requirement_Id:      "target-data",
+				Description:         "target data",
+				Applicability:       testingApplicability,
+				Steps:               []AssessmentStep{passingAssessmentStep},
+				Require_Target_Data: test.require,
+			}
+			result := a.Run(test.targetData, false)
+			if result != test.expectedResult {
+				t.Errorf("Expected Result to be %v, but got %v", test.expectedResult, result)
+			}
+			if a.Steps_Executed != test.expectedSteps {
+				t.Errorf("Expected %d steps to be executed, but got %d", test.expectedSteps, a.Steps_Executed)
+			}
+		})
+	}
+
+	t.Run("Control evaluation", func(t *testing.T) {
+		c := &ControlEvaluation{Require_Target_Data: true}
+		c.AddAssessment("target-data", "target data", testingApplicability, []AssessmentStep{passingAssessmentStep})
+		if err := c.TryEvaluate(nil, testingApplicability, false); err == nil {
+			t.Errorf("Expected an error for nil target data, but got nil")
+		}
+		if c.Result != Unknown {
+			t.Errorf("Expected the control Result to be %v, but got %v", Unknown, c.Result)
+		}
+		if c.Assessments[0].Require_Target_Data {
+			t.Errorf("Expected the control's setting not to be written to the assessment")
+		}
+	})
+}
+
+func TestManualStep(t *testing.T) {
+	a := &Assessment{
+		Requirement_Id: "manual",
+		Description:    "manual",
+		Applicability:  testingApplicability,
+		Steps:          []AssessmentStep{ManualStep("confirm the incident response plan was reviewed this year"), passingAssessmentStep},
+	}
+
+	result := a.Run(nil, false)
+
+	if result != NeedsReview {
+		t.Errorf("expected %s, got %s", NeedsReview, result)
+	}
+	if a.Steps_Executed != 2 || a.Halted {
+		t.Errorf("expected the manual step not to halt the run, but %d steps were executed", a.Steps_Executed)
+	}
+	if a.Review_Reason != ManualVerification {
+		t.Errorf("expected review reason %q, got %q", ManualVerification, a.Review_Reason)
+	}
+}
+
+func TestHaltOnUnknown(t *testing.T) {
+	tests := []struct {
+		testName      string
+		haltOnUnknown bool
+		expectedSteps int
+	}{
+		{testName: "Default continues after Unknown", haltOnUnknown: false, expectedSteps: 2},
+		{testName: "Halts on Unknown", haltOnUnknown: true, expectedSteps: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			a := &Assessment{
+				Requirement_Id:  "halt-on-unknown",
+				Description:     "halt on unknown",
+				Applicability:   testingApplicability,
+				Steps:           []AssessmentStep{unknownAssessmentStep, passingAssessmentStep},
+				Halt_On_Unknown: test.haltOnUnknown,
+			}
+			result := a.Run(nil, false)
+			if result != Unknown {
+				t.Errorf("expected %s, got %s", Unknown, result)
+			}
+			if a.Steps_Executed != test.expectedSteps {
+				t.Errorf("expected %d steps to be executed, got %d", test.expectedSteps, a.Steps_Executed)
+			}
+		})
+	}
+
+	t.Run("Control evaluation", func(t *testing.T) {
+		c := &ControlEvaluation{Halt_On_Unknown: true}
+		first := c.AddAssessment("first", "first", testingApplicability, []AssessmentStep{unknownAssessmentStep})
+		first.NewChange("change", "target", "description", nil, goodApplyFunc, goodRevertFunc).Apply()
+		second := c.AddAssessment("second", "second", testingApplicability, []AssessmentStep{passingAssessmentStep})
+		c.Evaluate(nil, testingApplicability, true)
+		if second.Steps_Executed != 0 {
+			t.Errorf("expected the evaluation to stop after an Unknown result")
+		}
+		if !first.Changes["change"].Reverted {
+			t.Errorf("expected cleanup to run after halting")
+		}
+		if first.Halt_On_Unknown {
+			t.Errorf("expected the control's setting not to be written to the assessment")
+		}
+	})
+}
+
+func TestAssessmentTimeout(t *testing.T) {
+	newAssessment := func(timeout time.Duration) *Assessment {
+		return &Assessment{
+			Requirement_Id:     "timeout",
+			Description:        "timeout",
+			Applicability:      testingApplicability,
+			Steps:              []AssessmentStep{passingAssessmentStep, passingAssessmentStep, passingAssessmentStep, passingAssessmentStep},
+			Assessment_Timeout: timeout,
+			Clock:              &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), interval: time.Second},
+		}
+	}
+
+	t.Run("Budget exceeded partway", func(t *testing.T) {
+		a := newAssessment(2500 * time.Millisecond)
+		a.Run(nil, false)
+
+		if a.Result != Unknown {
+			t.Errorf("Expected Unknown after the timeout, but got %s", a.Result)
+		}
+		if a.Steps_Executed != 2 {
+			t.Errorf("Expected 2 steps to run before the timeout, but got %d", a.Steps_Executed)
+		}
+		if !a.Halted {
+			t.Errorf("Expected Halted to be true after the timeout")
+		}
+		if !strings.Contains(a.Message, "timeout of 2.5s") {
+			t.Errorf("Expected a timeout message, but got %q", a.Message)
+		}
+		if a.Run_Duration != "4s" {
+			t.Errorf("Expected Run_Duration to include the elapsed time, but got %s", a.Run_Duration)
+		}
+	})
+	t.Run("Within budget", func(t *testing.T) {
+		a := newAssessment(time.Minute)
+		a.Run(nil, false)
+
+		if a.Result != Passed || a.Steps_Executed != 4 {
+			t.Errorf("Expected all 4 steps to pass within the budget, but got %s after %d steps", a.Result, a.Steps_Executed)
+		}
+	})
+	t.Run("Hung step", func(t *testing.T) {
+		a := &Assessment{
+			Requirement_Id:     "timeout",
+			Description:        "timeout",
+			Applicability:      testingApplicability,
+			Assessment_Timeout: 10 * time.Millisecond,
+			Context_Steps: []ContextStep{func(ctx context.Context, payload interface{}, _ map[string]*Change) StepResult {
+				<-ctx.Done()
+				return StepResult{Result: Unknown, Message: ctx.Err().Error()}
+			}},
+		}
+		done := make(chan struct{})
+		go func() {
+			a.Run(nil, false)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the timeout to stop a step that respects its context")
+		}
+		if a.Result != Unknown || !a.Interrupted || !a.Halted {
+			t.Errorf("Expected an interrupted Unknown result, but got %s (interrupted=%t, halted=%t)", a.Result, a.Interrupted, a.Halted)
+		}
+		if !strings.Contains(a.Message, "timeout of 10ms") {
+			t.Errorf("Expected a timeout message, but got %q", a.Message)
+		}
+	})
+}
+
+func TestHaltPredicate(t *testing.T) {
+	tests := []struct {
+		testName      string
+		predicate     HaltPredicate
+		expectedSteps int
+	}{
+		{testName: "Default halts on Failed", predicate: nil, expectedSteps: 3},
+		{testName: "Never halt", predicate: func(Result) bool { return false }, expectedSteps: 4},
+		{testName: "Halt on any non-Passed", predicate: func(r Result) bool { return r != Passed }, expectedSteps: 2},
+		{testName: "Halt on Failed or Unknown", predicate: func(r Result) bool { return r == Failed || r == Unknown }, expectedSteps: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			a := &Assessment{
+				Requirement_Id: "halt-predicate",
+				Description:    "halt predicate",
+				Applicability:  testingApplicability,
+				Steps:          []AssessmentStep{passingAssessmentStep, needsReviewAssessmentStep, failingAssessmentStep, passingAssessmentStep},
+				Halt_Predicate: test.predicate,
+			}
+			a.Run(nil, false)
+			if a.Steps_Executed != test.expectedSteps {
+				t.Errorf("expected %d steps to be executed, got %d", test.expectedSteps, a.Steps_Executed)
+			}
+		})
+	}
+
+	t.Run("Inherited from control evaluation", func(t *testing.T) {
+		c := &ControlEvaluation{Halt_Predicate: func(r Result) bool { return r != Passed }}
+		first := c.AddAssessment("first", "first", testingApplicability, []AssessmentStep{needsReviewAssessmentStep, passingAssessmentStep})
+		second := c.AddAssessment("second", "second", testingApplicability, []AssessmentStep{passingAssessmentStep})
+		c.Evaluate(nil, testingApplicability, false)
+		if first.Steps_Executed != 1 {
+			t.Errorf("expected the inherited predicate to halt the assessment, but %d steps were executed", first.Steps_Executed)
+		}
+		if first.Halt_Predicate != nil {
+			t.Errorf("expected the control's predicate not to be written to the assessment")
+		}
+		if second.Steps_Executed != 0 {
+			t.Errorf("expected the evaluation to stop after the predicate matched")
+		}
+	})
+}
+
+func TestAddSteps(t *testing.T) {
+	a := &Assessment{
+		Requirement_Id: "add-steps",
+		Description:    "add steps",
+		Applicability:  testingApplicability,
+		Steps:          []AssessmentStep{passingAssessmentStep},
+	}
+	a.AddSteps(passingAssessmentStep, needsReviewAssessmentStep)
+
+	if result := a.Run(nil, false); result != NeedsReview || a.Steps_Executed != 3 {
+		t.Errorf("expected 3 steps to run with result %s, got %d with result %s", NeedsReview, a.Steps_Executed, result)
+	}
+}
+
+func TestStepsTotal(t *testing.T) {
+	a := &Assessment{
+		Requirement_Id: "steps-total",
+		Description:    "steps total",
+		Applicability:  testingApplicability,
+		Steps:          []AssessmentStep{passingAssessmentStep, failingAssessmentStep, passingAssessmentStep},
+	}
+	a.Run(nil, false)
+
+	if a.Steps_Total != len(a.Steps) {
+		t.Errorf("expected Steps_Total to be %d, got %d", len(a.Steps), a.Steps_Total)
+	}
+	if a.Steps_Executed != 2 {
+		t.Errorf("expected 2 of %d steps to be executed, got %d", a.Steps_Total, a.Steps_Executed)
+	}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var restored struct {
+		Steps_Executed int `json:"steps-executed"`
+		Steps_Total    int `json:"steps-total"`
+	}
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if restored.Steps_Total != 3 || restored.Steps_Executed != 2 {
+		t.Errorf("expected the step counts to survive serialization, got %d of %d", restored.Steps_Executed, restored.Steps_Total)
+	}
+}
+
+func TestAssessmentString(t *testing.T) {
+	a := &Assessment{Requirement_Id: "CCC.C01.TR01", Result: Failed, Message: "bucket is public"}
+	if actual := fmt.Sprint(a); actual != "CCC.C01.TR01: Failed (bucket is public)" {
+		t.Errorf("unexpected format %q", actual)
+	}
+	a.Message = ""
+	if actual := a.String(); actual != "CCC.C01.TR01: Failed" {
+		t.Errorf("unexpected format %q", actual)
+	}
+}
+
+func TestDedupeSteps(t *testing.T) {
+	var runs int
+	countingStep := func(interface{}, map[string]*Change) (Result, string) {
+		runs++
+		return Passed, ""
+	}
+	requiredTag := func(tag string) AssessmentStep {
+		return func(interface{}, map[string]*Change) (Result, string) {
+			runs++
+			return Passed, tag
+		}
+	}
+	a := &Assessment{
+		Requirement_Id: "dedupe",
+		Description:    "dedupe",
+		Applicability:  testingApplicability,
+		Steps:          []AssessmentStep{countingStep, requiredTag("owner"), countingStep, requiredTag("environment"), countingStep},
+	}
+
+	if removed := a.DedupeSteps(); removed != 2 {
+		t.Errorf("expected 2 duplicate steps to be removed, got %d", removed)
+	}
+	a.Run(nil, false)
+
+	if runs != 3 || a.Steps_Executed != 3 {
+		t.Errorf("expected the repeated step to run once alongside both closures, got %d runs", runs)
+	}
+	if a.Message != "environment" {
+		t.Errorf("expected the original order to be preserved, got last message %q", a.Message)
+	}
+
+	reviewed := ManualStep("review the runbook")
+	manual := &Assessment{Steps: []AssessmentStep{reviewed, ManualStep("review the access list"), reviewed}}
+	if removed := manual.DedupeSteps(); removed != 1 || len(manual.Steps) != 2 {
+		t.Errorf("expected only the repeated closure to be removed, got %d removed and %d steps kept", removed, len(manual.Steps))
+	}
+}
+
+func TestProgressCallback(t *testing.T) {
+	var done []int
+	a := &Assessment{
+		Requirement_Id: "progress",
+		Description:    "progress",
+		Applicability:  testingApplicability,
+		Steps:          []AssessmentStep{passingAssessmentStep, needsReviewAssessmentStep, passingAssessmentStep},
+		Progress_Callback: func(d, total int) {
+			if total != 3 {
+				t.Errorf("expected a total of 3 steps, got %d", total)
+			}
+			done = append(done, d)
+		},
+	}
+	a.Run(nil, false)
+
+	if len(done) != len(a.Steps) {
+		t.Fatalf("expected the callback to fire %d times, got %d", len(a.Steps), len(done))
+	}
+	for i, d := range done {
+		if d != i+1 {
+			t.Errorf("expected done counts to increase by one, got %v", done)
+			break
+		}
+	}
+}
+
+func TestRerunPolicy(t *testing.T) {
+	newAssessment := func(policy RerunPolicy, applies *int) *Assessment {
+		a := &Assessment{
+			Requirement_Id: "rerun",
+			Description:    "rerun",
+			Applicability:  testingApplicability,
+			Rerun_Policy:   policy,
+		}
+		a.NewChange("change", "target", "description", nil, func() (interface{}, error) {
+			*applies++
+			return nil, nil
+		}, goodRevertFunc)
+		a.Steps = []AssessmentStep{
+			func(_ interface{}, changes map[string]*Change) (Result, string) {
+				changes["change"].Apply()
+				return Passed, ""
+			},
+		}
+		return a
+	}
+	tests := []struct {
+		policy          RerunPolicy
+		expectedSteps   int
+		expectedApplies int
+		expectedErr     error
+	}{
+		{policy: RerunAllowed, expectedSteps: 2, expectedApplies: 2},
+		{policy: RerunSkip, expectedSteps: 1, expectedApplies: 1},
+		{policy: RerunError, expectedSteps: 1, expectedApplies: 1, expectedErr: ErrAlreadyRun},
+	}
+	for _, test := range tests {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			var applies int
+			a := newAssessment(test.policy, &applies)
+			a.Run(nil, true)
+			a.RevertChanges()
+
+			result, err := a.TryRun(nil, true)
+			if !errors.Is(err, test.expectedErr) {
+				t.Errorf("expected error %v, got %v", test.expectedErr, err)
+			}
+			if result != Passed {
+				t.Errorf("expected %s, got %s", Passed, result)
+			}
+			if a.Steps_Executed != test.expectedSteps || applies != test.expectedApplies {
+				t.Errorf("expected %d steps and %d applies, got %d and %d", test.expectedSteps, test.expectedApplies, a.Steps_Executed, applies)
+			}
+		})
+	}
+
+	t.Run("Reset then rerun", func(t *testing.T) {
+		var applies int
+		a := newAssessment(RerunError, &applies)
+		a.Run(nil, true)
+		a.RevertChanges()
+
+		a.Reset()
+		if a.Result != NotRun || a.Steps_Executed != 0 {
+			t.Fatalf("expected Reset to return the assessment to %s, got %s after %d steps", NotRun, a.Result, a.Steps_Executed)
+		}
+		result, err := a.TryRun(nil, true)
+		if err != nil || result != Passed {
+			t.Errorf("expected the reset assessment to run again, got %s: %v", result, err)
+		}
+		if a.Steps_Executed != 1 || applies != 2 {
+			t.Errorf("expected 1 step and a second apply, got %d steps and %d applies", a.Steps_Executed, applies)
+		}
+	})
+
+	t.Run("Control evaluation", func(t *testing.T) {
+		var applies int
+		c := &ControlEvaluation{Name: "rerun", Control_Id: "rerun", Assessments: []*Assessment{newAssessment(RerunError, &applies)}}
+		if err := c.TryEvaluate(nil, testingApplicability, true); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := c.TryEvaluate(nil, testingApplicability, true); !errors.Is(err, ErrAlreadyRun) {
+			t.Errorf("expected %v, got %v", ErrAlreadyRun, err)
+		}
+	})
+
+	t.Run("Run duration without a run", func(t *testing.T) {
+		var applies int
+		a := newAssessment(RerunError, &applies)
+		a.Run_Duration = "1s"
+		if _, err := a.TryRun(nil, true); err != nil {
+			t.Errorf("expected an assessment that has not run to run, got %v", err)
+		}
+	})
+}
+
+func TestPrecheckOutcomes(t *testing.T) {
+	tests := []struct {
+		testName       string
+		assessment     *Assessment
+		misconfigured  bool
+		expectedResult Result
+	}{
+		{
+			testName:       "Valid and applicable",
+			assessment:     &Assessment{Requirement_Id: "valid", Description: "valid", Applicability: testingApplicability, Steps: []AssessmentStep{passingAssessmentStep}},
+			expectedResult: Passed,
+		},
+		{
+			testName:       "Valid but not applicable",
+			assessment:     &Assessment{Requirement_Id: "other", Description: "other", Applicability: []string{"other"}, Steps: []AssessmentStep{passingAssessmentStep}},
+			expectedResult: NotApplicable,
+		},
+		{
+			testName:       "Missing fields",
+			assessment:     &Assessment{Requirement_Id: "missing", Applicability: testingApplicability, Steps: []AssessmentStep{passingAssessmentStep}},
+			misconfigured:  true,
+			expectedResult: NotRun,
+		},
+		{
+			testName:       "Empty applicability tag",
+			assessment:     &Assessment{Requirement_Id: "empty", Description: "empty", Applicability: []string{""}, Steps: []AssessmentStep{passingAssessmentStep}},
+			misconfigured:  true,
+			expectedResult: NotRun,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			if test.assessment.IsMisconfigured() != test.misconfigured {
+				t.Errorf("expected IsMisconfigured to return %t", test.misconfigured)
+			}
+			err := test.assessment.Clone().precheck()
+			if errors.Is(err, ErrMisconfigured) != test.misconfigured {
+				t.Errorf("expected precheck to match ErrMisconfigured: %t, got %v", test.misconfigured, err)
+			}
+
+			c := &ControlEvaluation{Name: "precheck", Control_Id: "precheck", Assessments: []*Assessment{test.assessment}, Prefilter_Applicability: true}
+			err = c.TryEvaluate(nil, testingApplicability, false)
+			if errors.Is(err, ErrMisconfigured) != test.misconfigured {
+				t.Errorf("expected the control evaluation to match ErrMisconfigured: %t, got %v", test.misconfigured, err)
+			}
+			if !test.misconfigured && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if test.assessment.Result != test.expectedResult {
+				t.Errorf("expected %s, got %s", test.expectedResult, test.assessment.Result)
+			}
+		})
+	}
+}
@@ -1,30 +1,98 @@
 package layer4
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 )
 
 type ApplyFunc func() (interface{}, error)
 type RevertFunc func() error
 
+// ConfirmFunc is consulted before a change is applied, returning false to skip the change
+type ConfirmFunc func(change *Change) bool
+
 // Change is a struct that contains the data and functions associated with a single change to a target resource.
 type Change struct {
-	Target_Name string     // Required. TargetName is the name or ID of the resource or configuration that is to be changed
-	Description string     // Required. Description is a human-readable description of the change
+	Target_Name string     `json:"target-name" yaml:"target-name"` // Required. TargetName is the name or ID of the resource or configuration that is to be changed
+	Description string     `json:"description" yaml:"description"` // Required. Description is a human-readable description of the change
 	applyFunc   ApplyFunc  // Required. applyFunc is the function that will be executed to make the change
 	revertFunc  RevertFunc // Required. revertFunc is the function that will be executed to undo the change
 
-	Target_Object interface{} // TargetObject is supplemental data describing the object that was changed
-	Applied       bool        // Applied is true if the change was successfully applied at least once
-	Reverted      bool        // Reverted is true if the change was successfully reverted and not applied again
-	Error         error       // Error is used if any error occurred during the change
+	Target_Object interface{} `json:"target-object" yaml:"target-object"` // TargetObject is supplemental data describing the object that was changed
+	Applied       bool        `json:"applied" yaml:"applied"`             // Applied is true if the change was successfully applied at least once; prefer Status() when reporting
+	Reverted      bool        `json:"reverted" yaml:"reverted"`           // Reverted is true if the change was successfully reverted and not applied again; prefer Status() when reporting
+	Error         error       `json:"-" yaml:"-"`                         // Error is used if any error occurred during the change
+	Error_Message string      `json:"error" yaml:"error"`                 // Error_Message is the message of Error, which is what gets serialized
 	disallowed    bool        // Allowed may be disabled to prevent the change from being applied
+	readOnly      bool        // readOnly is true if the change was created by NewReadOnlyChange and never modifies its target
+
+	Rollback_Window time.Duration `json:"rollback-window" yaml:"rollback-window"` // Rollback_Window optionally limits how long the change may remain applied before CheckExpired reverts it
+	Expires_At      time.Time     `json:"expires-at" yaml:"expires-at"`           // Expires_At is the time after which CheckExpired will revert the change, set by Apply when a Rollback_Window is defined
+	Applied_At      time.Time     `json:"applied-at" yaml:"applied-at"`           // Applied_At is the time the change was most recently applied
+	Reverted_At     time.Time     `json:"reverted-at" yaml:"reverted-at"`         // Reverted_At is the time the change was most recently reverted, cleared when it is applied again
+	Rejected        bool          `json:"rejected" yaml:"rejected"`               // Rejected is true if the confirmation callback declined the most recent attempt to apply the change
+
+	clock       Clock       // clock provides the time for the timestamps above; set from the Assessment's Clock when it runs
+	confirmFunc ConfirmFunc // confirmFunc optionally gates each apply, as set by RequireConfirmation
+}
+
+// ChangeStatus is an enum summarizing the state of a Change
+type ChangeStatus int
+
+const (
+	ChangePending  ChangeStatus = iota // ChangePending means the change has not been applied
+	ChangeApplied                      // ChangeApplied means the change has been applied and not reverted
+	ChangeReverted                     // ChangeReverted means the change was applied and then successfully reverted
+	ChangeFailed                       // ChangeFailed means an error occurred while applying or reverting the change
+)
+
+var changeStatusToString = map[ChangeStatus]string{
+	ChangePending:  "Pending",
+	ChangeApplied:  "Applied",
+	ChangeReverted: "Reverted",
+	ChangeFailed:   "Failed",
+}
+
+func (s ChangeStatus) String() string {
+	return changeStatusToString[s]
+}
+
+// MarshalYAML ensures that ChangeStatus is serialized as a string in YAML
+func (s ChangeStatus) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+// MarshalJSON ensures that ChangeStatus is serialized as a string in JSON
+func (s ChangeStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// Status summarizes the Applied, Reverted, and Error fields into a single ChangeStatus.
+// This is the preferred way to report on the state of a change.
+func (c *Change) Status() ChangeStatus {
+	switch {
+	case c.Error != nil:
+		return ChangeFailed
+	case c.Applied && c.Reverted:
+		return ChangeReverted
+	case c.Applied:
+		return ChangeApplied
+	default:
+		return ChangePending
+	}
 }
 
 func (c *Change) Disallow() {
 	c.disallowed = true
 }
 
+// RequireConfirmation gates the change behind the provided callback, which is consulted each time
+// the change is about to be applied. If it returns false, the change is skipped and marked Rejected.
+func (c *Change) RequireConfirmation(confirm ConfirmFunc) {
+	c.confirmFunc = confirm
+}
+
 // Apply executes the Apply function for the change
 func (c *Change) Apply() (apppied bool) {
 	if c.disallowed {
@@ -32,16 +100,22 @@ func (c *Change) Apply() (apppied bool) {
 	}
 	err := c.precheck()
 	if err != nil {
-		c.Error = err
+		c.setError(err)
 		return
 	}
 	// Do nothing if the change has already been applied and not reverted
 	if c.Applied && !c.Reverted {
 		return true
 	}
+	if c.confirmFunc != nil {
+		c.Rejected = !c.confirmFunc(c)
+		if c.Rejected {
+			return
+		}
+	}
 	obj, err := c.applyFunc()
 	if err != nil {
-		c.Error = err
+		c.setError(err)
 		return
 	}
 	if obj != nil {
@@ -49,26 +123,105 @@ func (c *Change) Apply() (apppied bool) {
 	}
 	c.Applied = true
 	c.Reverted = false
+	c.Applied_At = c.now()
+	c.Reverted_At = time.Time{}
+	if c.Rollback_Window > 0 {
+		c.Expires_At = c.Applied_At.Add(c.Rollback_Window)
+	}
 	return true
 }
 
+// setError records the error in Error, along with its message in Error_Message; a nil error clears both
+func (c *Change) setError(err error) {
+	c.Error = err
+	c.Error_Message = ""
+	if err != nil {
+		c.Error_Message = err.Error()
+	}
+}
+
 // Revert executes the Revert function for the change
 func (c *Change) Revert() {
 	err := c.precheck()
 	if err != nil {
-		c.Error = err
+		c.setError(err)
 		return
 	}
-	// Do nothing if the change has not been applied
-	if !c.Applied {
+	// Do nothing if the change has not been applied, or has already been reverted
+	if !c.Applied || c.Reverted {
 		return
 	}
 	err = c.revertFunc()
 	if err != nil {
-		c.Error = err
+		c.setError(err)
+		return
+	}
+	c.Reverted = true
+	c.Reverted_At = c.now()
+}
+
+// ForceRevert executes the Revert function for the change even if it was never marked as applied,
+// such as when an apply function mutated the target before failing. Previous errors do not prevent the attempt.
+// The revert function must therefore be idempotent and safe to call when the change was never made.
+func (c *Change) ForceRevert() {
+	if c.revertFunc == nil {
+		c.setError(fmt.Errorf("revertFunc must be defined to revert change to %s", c.Target_Name))
+		return
+	}
+	if c.Reverted {
+		return
+	}
+	err := c.revertFunc()
+	if err != nil {
+		c.setError(err)
 		return
 	}
 	c.Reverted = true
+	c.Reverted_At = c.now()
+}
+
+// CheckExpired reverts the change if it is applied and its Rollback_Window has elapsed,
+// returning true if the change was expired and reverted.
+// Change is not safe for concurrent use, so callers polling CheckExpired from another goroutine
+// must ensure that no step is applying or reverting the same change at the same time.
+func (c *Change) CheckExpired() (reverted bool) {
+	if c.Expires_At.IsZero() || !c.Applied || c.Reverted || c.now().Before(c.Expires_At) {
+		return false
+	}
+	c.Revert()
+	return c.Reverted
+}
+
+// now returns the current time from the change's clock, or the system clock if none is set
+func (c *Change) now() time.Time {
+	if c.clock == nil {
+		return defaultClock.Now()
+	}
+	return c.clock.Now()
+}
+
+// ChangeCounts tallies the changes made across an evaluation, for remediation reporting
+type ChangeCounts struct {
+	Applied         int `json:"applied" yaml:"applied"`                 // Applied is the number of changes that were applied at least once
+	Reverted        int `json:"reverted" yaml:"reverted"`               // Reverted is the number of applied changes that were successfully reverted
+	Revert_Failures int `json:"revert-failures" yaml:"revert-failures"` // Revert_Failures is the number of applied changes that could not be reverted
+	Still_Applied   int `json:"still-applied" yaml:"still-applied"`     // Still_Applied is the number of applied changes that have not been reverted or failed to revert
+	Apply_Failures  int `json:"apply-failures" yaml:"apply-failures"`   // Apply_Failures is the number of changes that failed before they could be applied
+}
+
+// clone returns a copy of the change in its pending state, sharing the apply and revert functions
+func (c *Change) clone() *Change {
+	return &Change{
+		Target_Name:   c.Target_Name,
+		Description:   c.Description,
+		applyFunc:     c.applyFunc,
+		revertFunc:    c.revertFunc,
+		Target_Object: c.Target_Object,
+
+		Rollback_Window: c.Rollback_Window,
+		confirmFunc:     c.confirmFunc,
+		readOnly:        c.readOnly,
+	}
 }
 
 // precheck verifies that the applyFunc and revertFunc are defined for the change
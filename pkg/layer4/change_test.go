@@ -1,6 +1,12 @@
 package layer4
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
 
 var changesTestData = []struct {
 	testName string
@@ -114,3 +120,289 @@ func TestRevert(t *testing.T) {
 		})
 	}
 }
+
+func TestRepeatedApplyAndRevert(t *testing.T) {
+	var applyCount, revertCount int
+	change := &Change{
+		Target_Name: "countingChange",
+		Description: "description placeholder",
+		applyFunc: func() (interface{}, error) {
+			applyCount++
+			return nil, nil
+		},
+		revertFunc: func() error {
+			revertCount++
+			return nil
+		},
+	}
+
+	change.Apply()
+	if !change.Apply() {
+		t.Errorf("Expected a repeated apply to report the change as applied")
+	}
+	if applyCount != 1 {
+		t.Errorf("Expected applyFunc to run once, but it ran %d times", applyCount)
+	}
+
+	change.Revert()
+	change.Revert()
+	if revertCount != 1 {
+		t.Errorf("Expected revertFunc to run once, but it ran %d times", revertCount)
+	}
+	if !change.Reverted || change.Error != nil {
+		t.Errorf("Expected change to be cleanly reverted, but got reverted=%t, error=%v", change.Reverted, change.Error)
+	}
+
+	change.Apply()
+	if applyCount != 2 {
+		t.Errorf("Expected applyFunc to run again after a revert, but it ran %d times", applyCount)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		testName string
+		change   *Change
+		expected ChangeStatus
+	}{
+		{
+			testName: "Not applied",
+			change:   &Change{},
+			expected: ChangePending,
+		},
+		{
+			testName: "Applied",
+			change:   &Change{Applied: true},
+			expected: ChangeApplied,
+		},
+		{
+			testName: "Applied and reverted",
+			change:   &Change{Applied: true, Reverted: true},
+			expected: ChangeReverted,
+		},
+		{
+			testName: "Error without applying",
+			change:   &Change{Error: errors.New("error")},
+			expected: ChangeFailed,
+		},
+		{
+			testName: "Applied with error",
+			change:   &Change{Applied: true, Error: errors.New("error")},
+			expected: ChangeFailed,
+		},
+		{
+			testName: "Applied and reverted with error",
+			change:   &Change{Applied: true, Reverted: true, Error: errors.New("error")},
+			expected: ChangeFailed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			if test.change.Status() != test.expected {
+				t.Errorf("Expected status %s, but got %s", test.expected, test.change.Status())
+			}
+		})
+	}
+}
+
+func TestCheckExpired(t *testing.T) {
+	newChange := func() *Change {
+		return &Change{
+			Target_Name:     "firewall",
+			Description:     "temporarily open the firewall",
+			applyFunc:       goodApplyFunc,
+			revertFunc:      goodRevertFunc,
+			Rollback_Window: time.Hour,
+		}
+	}
+
+	t.Run("Not yet expired", func(t *testing.T) {
+		change := newChange()
+		change.Apply()
+		if change.Expires_At.IsZero() {
+			t.Fatalf("Expected Apply to set Expires_At when a Rollback_Window is defined")
+		}
+		if change.CheckExpired() || change.Reverted {
+			t.Errorf("Expected change not to be reverted before its Rollback_Window elapsed")
+		}
+	})
+	t.Run("Expired", func(t *testing.T) {
+		a := &Assessment{Changes: map[string]*Change{"firewall": newChange()}}
+		a.Changes["firewall"].Apply()
+		a.Changes["firewall"].Expires_At = time.Now().Add(-time.Second)
+		if reverted := a.RevertExpiredChanges(); reverted != 1 {
+			t.Errorf("Expected 1 expired change to be reverted, but got %d", reverted)
+		}
+		if !a.Changes["firewall"].Reverted {
+			t.Errorf("Expected the expired change to be reverted")
+		}
+	})
+	t.Run("No Rollback_Window", func(t *testing.T) {
+		change := newChange()
+		change.Rollback_Window = 0
+		change.Apply()
+		if change.CheckExpired() {
+			t.Errorf("Expected a change without a Rollback_Window never to expire")
+		}
+	})
+}
+
+func TestForceRevert(t *testing.T) {
+	tests := []struct {
+		name             string
+		applyFunc        ApplyFunc
+		revertFunc       RevertFunc
+		apply            bool
+		expectedReverted bool
+	}{
+		{name: "Never applied", applyFunc: goodApplyFunc, revertFunc: goodRevertFunc, expectedReverted: true},
+		{name: "Apply failed", applyFunc: badApplyFunc, revertFunc: goodRevertFunc, apply: true, expectedReverted: true},
+		{name: "Revert failed", applyFunc: goodApplyFunc, revertFunc: badRevertFunc, expectedReverted: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := &Assessment{}
+			change := a.NewChange("change", "target", "description", nil, test.applyFunc, test.revertFunc)
+			if test.apply {
+				change.Apply()
+			}
+			if corrupted := a.ForceRevertAll(); corrupted == test.expectedReverted {
+				t.Errorf("Expected corrupted to be %v, but got %v", !test.expectedReverted, corrupted)
+			}
+			if change.Reverted != test.expectedReverted {
+				t.Errorf("Expected Reverted to be %v, but got %v", test.expectedReverted, change.Reverted)
+			}
+		})
+	}
+
+	t.Run("Force cleanup", func(t *testing.T) {
+		var reverts int
+		a := &Assessment{}
+		a.NewChange("change", "target", "description", nil, goodApplyFunc, func() error {
+			reverts++
+			return nil
+		})
+		c := &ControlEvaluation{Assessments: []*Assessment{a}}
+
+		c.Cleanup()
+		if reverts != 0 {
+			t.Fatalf("Expected Cleanup not to revert an unapplied change")
+		}
+		c.ForceCleanup()
+		if reverts != 1 {
+			t.Errorf("Expected ForceCleanup to revert the unapplied change once, but got %d reverts", reverts)
+		}
+		if c.Corrupted_State {
+			t.Errorf("Expected Corrupted_State to be false after a successful forced cleanup")
+		}
+	})
+}
+
+func TestChangeTimestamps(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := &Assessment{
+		Requirement_Id: "timestamps",
+		Description:    "timestamps",
+		Applicability:  testingApplicability,
+		Clock:          &fakeClock{now: start, interval: time.Second},
+	}
+	change := a.NewChange("change", "target", "description", nil, goodApplyFunc, goodRevertFunc)
+	a.Steps = []AssessmentStep{
+		func(interface{}, map[string]*Change) (Result, string) {
+			change.Apply()
+			return Passed, ""
+		},
+	}
+
+	a.Run(nil, true)
+	if !change.Reverted_At.IsZero() {
+		t.Errorf("Expected Reverted_At to be unset before the change is reverted")
+	}
+	a.RevertChanges()
+
+	if change.Applied_At.IsZero() || change.Reverted_At.IsZero() {
+		t.Fatalf("Expected both timestamps to be set, but got Applied_At=%v, Reverted_At=%v", change.Applied_At, change.Reverted_At)
+	}
+	if !change.Applied_At.After(start) || !change.Reverted_At.After(change.Applied_At) {
+		t.Errorf("Expected the change to be applied and then reverted in order, but got Applied_At=%v, Reverted_At=%v", change.Applied_At, change.Reverted_At)
+	}
+
+	data, err := json.Marshal(change)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), `"applied-at":"2024-01-01T00:00:`) || !strings.Contains(string(data), `"reverted-at":"2024-01-01T00:00:`) {
+		t.Errorf("Expected the timestamps to be serialized, but got %s", data)
+	}
+
+	change.Apply()
+	if !change.Reverted_At.IsZero() {
+		t.Errorf("Expected Reverted_At to be cleared when the change is applied again")
+	}
+}
+
+func TestRequireConfirmation(t *testing.T) {
+	tests := []struct {
+		name            string
+		confirm         bool
+		expectedApplied bool
+	}{
+		{name: "Confirmed", confirm: true, expectedApplied: true},
+		{name: "Rejected", confirm: false, expectedApplied: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var applies int
+			var asked *Change
+			change := &Change{
+				Target_Name: "firewall",
+				Description: "close the public port",
+				applyFunc: func() (interface{}, error) {
+					applies++
+					return nil, nil
+				},
+				revertFunc: goodRevertFunc,
+			}
+			change.RequireConfirmation(func(c *Change) bool {
+				asked = c
+				return test.confirm
+			})
+
+			if applied := change.Apply(); applied != test.expectedApplied || change.Applied != test.expectedApplied {
+				t.Errorf("Expected applied to be %t, but got %t", test.expectedApplied, applied)
+			}
+			if asked != change {
+				t.Errorf("Expected the confirmation callback to receive the change")
+			}
+			if change.Rejected == test.confirm {
+				t.Errorf("Expected Rejected to be %t, but got %t", !test.confirm, change.Rejected)
+			}
+			if test.confirm != (applies == 1) {
+				t.Errorf("Expected applyFunc to run only when confirmed, but it ran %d times", applies)
+			}
+			if change.Error != nil {
+				t.Errorf("Expected a rejected change not to be recorded as an error, but got %v", change.Error)
+			}
+		})
+	}
+}
+
+func TestChangeSummary(t *testing.T) {
+	first := &Assessment{Requirement_Id: "first"}
+	first.NewChange("reverted", "target", "description", nil, goodApplyFunc, goodRevertFunc).Apply()
+	second := &Assessment{Requirement_Id: "second"}
+	second.NewChange("revert-fails", "target", "description", nil, goodApplyFunc, badRevertFunc).Apply()
+	second.NewChange("apply-fails", "target", "description", nil, badApplyFunc, goodRevertFunc).Apply()
+	second.NewChange("pending", "target", "description", nil, goodApplyFunc, goodRevertFunc)
+	third := &Assessment{Requirement_Id: "third"}
+	third.NewChange("still-applied", "target", "description", nil, goodApplyFunc, goodRevertFunc).Apply()
+
+	first.RevertChanges()
+	second.Changes["revert-fails"].Revert()
+	c := &ControlEvaluation{Assessments: []*Assessment{first, second, third}}
+
+	expected := ChangeCounts{Applied: 3, Reverted: 1, Revert_Failures: 1, Still_Applied: 1, Apply_Failures: 1}
+	if counts := c.ChangeSummary(); counts != expected {
+		t.Errorf("Expected %+v, but got %+v", expected, counts)
+	}
+}
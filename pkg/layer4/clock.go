@@ -0,0 +1,18 @@
+package layer4
+
+import "time"
+
+// Clock provides the current time, allowing time-dependent behavior such as Run_Duration to be controlled in tests
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the default Clock, backed by the system time
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// defaultClock is used whenever a Clock has not been provided
+var defaultClock Clock = realClock{}
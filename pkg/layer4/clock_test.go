@@ -0,0 +1,34 @@
+package layer4
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock advances by a fixed interval each time Now is called
+type fakeClock struct {
+	now      time.Time
+	interval time.Duration
+}
+
+func (f *fakeClock) Now() time.Time {
+	now := f.now
+	f.now = f.now.Add(f.interval)
+	return now
+}
+
+func TestRunDurationWithClock(t *testing.T) {
+	a := &Assessment{
+		Requirement_Id: "clock",
+		Description:    "clock",
+		Applicability:  testingApplicability,
+		Steps:          []AssessmentStep{passingAssessmentStep},
+		Clock:          &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), interval: 1500 * time.Millisecond},
+	}
+
+	a.Run(nil, false)
+
+	if a.Run_Duration != "1.5s" {
+		t.Errorf("Expected Run_Duration to be 1.5s, but got %s", a.Run_Duration)
+	}
+}
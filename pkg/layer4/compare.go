@@ -0,0 +1,49 @@
+package layer4
+
+import (
+	"reflect"
+	"time"
+)
+
+// Normalized returns a copy of the Assessment with volatile fields cleared, so that the results
+// of two runs can be compared. Run_Duration, steps, and the configured matcher, clock, halt predicate,
+// and progress callback are cleared, and changes are copied without their apply and revert functions,
+// expiry times, or timestamps.
+func (a *Assessment) Normalized() *Assessment {
+	normalized := *a
+	normalized.Run_Duration = ""
+	normalized.Steps = nil
+	normalized.Context_Steps = nil
+	normalized.Applicability_Matcher = nil
+	normalized.Clock = nil
+	normalized.Halt_Predicate = nil
+	normalized.Progress_Callback = nil
+	normalized.Step_Errors = nil
+	normalized.ran = false
+	if a.Changes != nil {
+		normalized.Changes = make(map[string]*Change, len(a.Changes))
+		for name, change := range a.Changes {
+			copied := *change
+			copied.applyFunc = nil
+			copied.revertFunc = nil
+			copied.Error = nil
+			copied.Expires_At = time.Time{}
+			copied.Applied_At = time.Time{}
+			copied.Reverted_At = time.Time{}
+			copied.clock = nil
+			copied.confirmFunc = nil
+			normalized.Changes[name] = &copied
+		}
+	}
+	normalized.Sub_Assessments = nil
+	for _, child := range a.Sub_Assessments {
+		normalized.Sub_Assessments = append(normalized.Sub_Assessments, child.Normalized())
+	}
+	return &normalized
+}
+
+// EqualResults returns true if the two assessments are equal after normalization,
+// ignoring volatile fields such as Run_Duration and step function pointers
+func EqualResults(a, b *Assessment) bool {
+	return reflect.DeepEqual(a.Normalized(), b.Normalized())
+}
@@ -0,0 +1,32 @@
+package layer4
+
+import "testing"
+
+func TestEqualResults(t *testing.T) {
+	newAssessment := func() *Assessment {
+		a := &Assessment{
+			Requirement_Id: "compare",
+			Description:    "compare",
+			Applicability:  testingApplicability,
+			Steps:          []AssessmentStep{passingAssessmentStep},
+		}
+		a.NewChange("change", "target", "description", nil, goodApplyFunc, goodRevertFunc).Apply()
+		return a
+	}
+	first, second := newAssessment(), newAssessment()
+	first.Run(nil, false)
+	second.Run(nil, false)
+	second.Run_Duration = "1h0m0s"
+
+	if !EqualResults(first, second) {
+		t.Errorf("Expected two runs to be equal regardless of duration")
+	}
+
+	second.Message = "different"
+	if EqualResults(first, second) {
+		t.Errorf("Expected assessments with different messages not to be equal")
+	}
+	if first.Run_Duration == "" || len(first.Steps) != 1 {
+		t.Errorf("Expected normalization not to modify the original assessment")
+	}
+}
@@ -1,21 +1,67 @@
 package layer4
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"maps"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 )
 
+// MessageFormatter computes a control-level message from the assessments of an evaluation
+type MessageFormatter func(assessments []*Assessment) string
+
+// SummaryMessage is a MessageFormatter that counts the assessments with each result, such as "2 Passed, 1 Failed"
+func SummaryMessage(assessments []*Assessment) string {
+	return summarizeResults(assessments)
+}
+
+// ErrNoAssessments is returned when a control evaluation is run without any assessments
+var ErrNoAssessments = errors.New("control evaluation has no assessments")
+
 // ControlEvaluation is a struct that contains all assessment results, organinzed by name
 type ControlEvaluation struct {
-	Name              string        // TestSuiteName is the human-readable name or description of the control evaluation
-	Control_Id        string        // Control_Id is the unique identifier for the control being evaluated
-	Result            Result        // Result is true if all testSets in the testSuite passed
-	Message           string        // Message is the human-readable result of the final assessment to run in this evaluation
-	Corrupted_State   bool          // BadState is true if any testSet failed to revert at the end of the testSuite
-	Remediation_Guide string        // Remediation_Guide is the URL to the documentation for this evaluation
-	Assessments       []*Assessment // Control_Evaluations is a map of testSet names to their results
+	Name                     string               `json:"name" yaml:"name"`                                         // TestSuiteName is the human-readable name or description of the control evaluation
+	Control_Id               string               `json:"control-id" yaml:"control-id"`                             // Control_Id is the unique identifier for the control being evaluated
+	Result                   Result               `json:"result" yaml:"result"`                                     // Result is true if all testSets in the testSuite passed
+	Message                  string               `json:"message" yaml:"message"`                                   // Message is the human-readable result of the final assessment to run in this evaluation
+	Corrupted_State          bool                 `json:"corrupted-state" yaml:"corrupted-state"`                   // BadState is true if any testSet failed to revert at the end of the testSuite
+	Remediation_Guide        string               `json:"remediation-guide" yaml:"remediation-guide"`               // Remediation_Guide is the URL to the documentation for this evaluation
+	Assessments              []*Assessment        `json:"assessments" yaml:"assessments"`                           // Control_Evaluations is a map of testSet names to their results
+	Labels                   map[string]string    `json:"labels" yaml:"labels"`                                     // Labels is arbitrary key/value metadata used for filtering and grouping evaluations
+	Cleanup_Error_Messages   []string             `json:"cleanup-error-messages" yaml:"cleanup-error-messages"`     // Cleanup_Error_Messages is the message of each error in Cleanup_Errors, which is what gets serialized
+	Complete                 bool                 `json:"complete" yaml:"complete"`                                 // Complete is true once an evaluation has finished with every applicable assessment having a Result other than NotRun
+	Require_Target_Data      bool                 `json:"require-target-data" yaml:"require-target-data"`           // Require_Target_Data applies Require_Target_Data to every assessment as it runs, halting them as Unknown if the target data is nil
+	Exclusive_Change_Targets bool                 `json:"exclusive-change-targets" yaml:"exclusive-change-targets"` // Exclusive_Change_Targets makes Validate reject changes that share a Target_Name, rather than leaving callers to check ChangeConflicts
+	Halt_On_Unknown          bool                 `json:"halt-on-unknown" yaml:"halt-on-unknown"`                   // Halt_On_Unknown applies Halt_On_Unknown to every assessment as it runs and stops the evaluation after an assessment returns Unknown
+	Revert_Policy            *RevertPolicy        `json:"revert-policy" yaml:"revert-policy"`                       // Revert_Policy optionally retries failed reverts during Cleanup and decides whether to continue after a failure
+	Applicability_Summary    ApplicabilitySummary `json:"applicability-summary" yaml:"applicability-summary"`       // Applicability_Summary records the target applicability and how many assessments it matched during the most recent evaluation
+	Prefilter_Applicability  bool                 `json:"prefilter-applicability" yaml:"prefilter-applicability"`   // Prefilter_Applicability marks each assessment that does not apply to the target NotApplicable before any assessment runs; by default they are skipped and left NotRun
+
+	Before_Assessment       func(*Assessment)      `json:"-" yaml:"-"` // Before_Assessment is an optional hook invoked immediately before each assessment is run
+	After_Assessment        func(*Assessment)      `json:"-" yaml:"-"` // After_Assessment is an optional hook invoked after each assessment has run and its Result is set
+	Applicability_Matcher   ApplicabilityMatcher   `json:"-" yaml:"-"` // Applicability_Matcher is used for any assessment that does not set its own matcher
+	Interrupt_Handler       *InterruptHandler      `json:"-" yaml:"-"` // Interrupt_Handler configures the response to termination signals; defaults are used when nil
+	Metrics                 MetricsSink            `json:"-" yaml:"-"` // Metrics optionally receives counters and durations as the evaluation runs
+	Applicability_Extractor ApplicabilityExtractor `json:"-" yaml:"-"` // Applicability_Extractor optionally derives the target applicability from the target data when none is provided
+	Message_Formatter       MessageFormatter       `json:"-" yaml:"-"` // Message_Formatter optionally computes the Message after evaluation; by default it is the last assessment's Message
+	Halt_Predicate          HaltPredicate          `json:"-" yaml:"-"` // Halt_Predicate is used for any assessment that does not set its own, and decides which assessment results stop the evaluation
+	Setup                   func() error           `json:"-" yaml:"-"` // Setup is an optional hook invoked once before any assessment runs; an error aborts the evaluation as Unknown
+	Teardown                func()                 `json:"-" yaml:"-"` // Teardown is an optional hook invoked once after the evaluation and its cleanup, even if it ended early or Setup returned an error
+	Cleanup_Errors          []error                `json:"-" yaml:"-"` // Cleanup_Errors describes each change that could not be reverted during the most recent cleanup, including its target
+
+	cleanupMu sync.Mutex             // cleanupMu serializes calls to Cleanup
+	indexMu   sync.Mutex             // indexMu guards the index
+	index     map[string]*Assessment // index maps each Requirement_Id to the first assessment with that ID
+	indexed   int                    // indexed is the number of assessments in the index when it was last built
 }
 
 func (c *ControlEvaluation) AddAssessment(requirementId string, description string, applicability []string, steps []AssessmentStep) (assessment *Assessment) {
@@ -25,63 +71,853 @@ func (c *ControlEvaluation) AddAssessment(requirementId string, description stri
 		c.Message = err.Error()
 	}
 	c.Assessments = append(c.Assessments, assessment)
+	c.indexMu.Lock()
+	defer c.indexMu.Unlock()
+	if c.index != nil && c.indexed == len(c.Assessments)-1 {
+		if _, ok := c.index[requirementId]; !ok {
+			c.index[requirementId] = assessment
+		}
+		c.indexed++
+	}
 	return
 }
 
-// Evaluate runs each step in each assessment, updating the relevant fields on the control evaluation.
+// String returns a compact summary of the ControlEvaluation for logs, such as "CCC.C01: Passed (3 assessments)"
+func (c *ControlEvaluation) String() string {
+	return fmt.Sprintf("%s: %s (%d assessments)", c.Control_Id, c.Result, len(c.Assessments))
+}
+
+// GetAssessment returns the assessment with the provided requirement ID, or false if there is none.
+// If several assessments share the ID, the first is returned.
+// Lookups use an index maintained by AddAssessment, which is rebuilt if Assessments has been modified directly.
+func (c *ControlEvaluation) GetAssessment(requirementId string) (*Assessment, bool) {
+	c.indexMu.Lock()
+	defer c.indexMu.Unlock()
+	if c.index == nil || c.indexed != len(c.Assessments) {
+		c.rebuildIndex()
+	}
+	assessment, ok := c.index[requirementId]
+	if ok && assessment.Requirement_Id == requirementId {
+		return assessment, true
+	}
+	// the index may be stale if assessments were replaced or renamed in place
+	c.rebuildIndex()
+	assessment, ok = c.index[requirementId]
+	return assessment, ok
+}
+
+// rebuildIndex indexes the assessments by Requirement_Id; the caller must hold indexMu
+func (c *ControlEvaluation) rebuildIndex() {
+	c.index = make(map[string]*Assessment, len(c.Assessments))
+	for _, assessment := range c.Assessments {
+		if _, ok := c.index[assessment.Requirement_Id]; !ok {
+			c.index[assessment.Requirement_Id] = assessment
+		}
+	}
+	c.indexed = len(c.Assessments)
+}
+
+// ApplicableAssessments returns the subset of assessments that apply to the provided applicability.
+// `userApplicability` is a slice of strings that determine when the assessment is applicable.
+func (c *ControlEvaluation) ApplicableAssessments(userApplicability []string) (applicable []*Assessment) {
+	for _, assessment := range c.Assessments {
+		if _, ok := assessment.matchApplicabilityWithMatcher(userApplicability, c.settingsFor(assessment).matcher); ok {
+			applicable = append(applicable, assessment)
+		}
+	}
+	return
+}
+
+// Evaluate runs each step in each applicable assessment, updating the relevant fields on the control evaluation.
+// Assessments that do not apply are skipped entirely and counted in the Applicability_Summary; they are left NotRun
+// unless Prefilter_Applicability marks them NotApplicable. If no assessment applies, the Result is NotApplicable.
 // It will halt if a step returns a failed result.
 // `targetData` is the data that the assessment will be run against.
-// `userApplicability` is a slice of strings that determine when the assessment is applicable.
+// `userApplicability` is a slice of strings that determine when the assessment is applicable;
+// a target may carry several applicability values at once, and an assessment runs if it matches any of them.
 // `changesAllowed` determines whether the assessment is allowed to execute its changes.
+// Applied changes are reverted before returning, even if a step panics.
 func (c *ControlEvaluation) Evaluate(targetData interface{}, userApplicability []string, changesAllowed bool) {
-	if len(c.Assessments) == 0 {
+	_ = c.evaluate(context.Background(), targetData, userApplicability, changesAllowed, evaluateOptions{})
+}
+
+// EvaluateWithContext behaves like Evaluate, passing ctx down to each assessment's RunWithContext.
+// Cancellation is checked between assessments; once ctx is done the remaining assessments are skipped,
+// the Result is set to Unknown, and Cleanup still runs to revert any applied changes.
+// The returned error includes the context's error if the evaluation was cancelled.
+func (c *ControlEvaluation) EvaluateWithContext(ctx context.Context, targetData interface{}, userApplicability []string, changesAllowed bool) error {
+	return c.evaluate(ctx, targetData, userApplicability, changesAllowed, evaluateOptions{})
+}
+
+// TryEvaluate behaves like Evaluate, but also returns an error if the evaluation could not be performed
+// as intended, such as when there are no assessments, an assessment fails its precheck, or a step panics.
+// A nil error means the evaluation ran cleanly, regardless of whether the control passed.
+// The control evaluation fields are updated in the same way as Evaluate.
+func (c *ControlEvaluation) TryEvaluate(targetData interface{}, userApplicability []string, changesAllowed bool) (err error) {
+	defer c.recoverPanic(&err)
+	return c.evaluate(context.Background(), targetData, userApplicability, changesAllowed, evaluateOptions{})
+}
+
+// recoverPanic recovers from a panic during an evaluation, recording it as an Unknown result and returning it through err.
+// It must be deferred directly.
+func (c *ControlEvaluation) recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		c.Result = Unknown
+		c.Message = fmt.Sprintf("evaluation panicked: %v", r)
+		*err = errors.New(c.Message)
+	}
+}
+
+// EvaluateOne runs only the assessment with the provided requirement ID, reverting its changes afterward.
+// The control evaluation's Result and Message are not updated, though Corrupted_State is set if the
+// assessment's changes could not be reverted. An error is returned if no assessment has the requirement ID,
+// if the assessment does not apply to the provided applicability, or if the assessment could not be run.
+func (c *ControlEvaluation) EvaluateOne(requirementId string, targetData interface{}, userApplicability []string, changesAllowed bool) (*Assessment, error) {
+	assessment, ok := c.GetAssessment(requirementId)
+	if !ok {
+		return nil, fmt.Errorf("no assessment found with requirement id %s", requirementId)
+	}
+	userApplicability = c.targetApplicability(targetData, userApplicability)
+	if err := validateApplicability(userApplicability); err != nil {
+		return nil, fmt.Errorf("invalid target applicability: %w", err)
+	}
+	var applicable bool
+	assessment.Matched_Applicability, applicable = assessment.matchApplicabilityWithMatcher(userApplicability, c.settingsFor(assessment).matcher)
+	if !applicable {
+		assessment.Result = NotApplicable
+		return assessment, fmt.Errorf("assessment %s does not apply to %v", requirementId, userApplicability)
+	}
+
+	stop := c.closeHandler()
+	defer stop()
+	_, err := c.runAssessment(c.withMetadata(context.Background()), assessment, targetData, changesAllowed)
+	if assessment.RevertChanges() {
+		c.Corrupted_State = true
+		c.addCleanupErrors(assessment.revertErrors()...)
+	}
+	return assessment, err
+}
+
+// AssessmentSortKey selects the field used by SortAssessments
+type AssessmentSortKey int
+
+const (
+	SortById            AssessmentSortKey = iota // SortById orders assessments by Requirement_Id
+	SortBySeverity                               // SortBySeverity orders assessments by Result, most severe first; only meaningful after evaluation
+	SortByApplicability                          // SortByApplicability orders assessments by their Applicability tags
+)
+
+// SortAssessments reorders the assessments by the selected key, preserving insertion order for ties.
+// Assessments with Depends_On are still run after their dependencies regardless of order.
+// SortBySeverity uses each assessment's Result, which is NotRun for every assessment before evaluation,
+// so it leaves unevaluated assessments in insertion order; use it to order reports rather than runs.
+func (c *ControlEvaluation) SortAssessments(key AssessmentSortKey) {
+	less := func(a, b *Assessment) bool {
+		switch key {
+		case SortBySeverity:
+			return a.Result.Less(b.Result)
+		case SortByApplicability:
+			return strings.Join(a.Applicability, ",") < strings.Join(b.Applicability, ",")
+		default:
+			return a.Requirement_Id < b.Requirement_Id
+		}
+	}
+	sort.SliceStable(c.Assessments, func(i, j int) bool {
+		return less(c.Assessments[i], c.Assessments[j])
+	})
+}
+
+// AssessmentProgress describes an assessment that has finished running during an evaluation
+type AssessmentProgress struct {
+	Index          int    // Index is the position of the assessment in the ControlEvaluation's Assessments
+	Requirement_Id string // Requirement_Id is the unique identifier for the requirement that was tested
+	Result         Result // Result is the result of the assessment
+}
+
+// EvaluateStream runs Evaluate in a new goroutine and returns a channel that receives a progress event
+// after each assessment finishes running. The channel is closed once the evaluation is complete,
+// at which point the control evaluation fields have been updated as described by Evaluate.
+func (c *ControlEvaluation) EvaluateStream(targetData interface{}, userApplicability []string, changesAllowed bool) <-chan AssessmentProgress {
+	progress := make(chan AssessmentProgress, len(c.Assessments))
+	go func() {
+		defer close(progress)
+		_ = c.evaluate(context.Background(), targetData, userApplicability, changesAllowed, evaluateOptions{
+			onProgress: func(event AssessmentProgress) {
+				progress <- event
+			},
+		})
+	}()
+	return progress
+}
+
+// evaluateOptions adjusts how evaluate runs
+type evaluateOptions struct {
+	onProgress func(AssessmentProgress) // onProgress is called after each assessment runs, if provided
+	resume     bool                     // resume skips assessments that already have a Result other than NotRun
+	provider   AssessmentProvider       // provider supplies additional assessments to run after those in Assessments, if provided
+
+	sharedInterrupt bool // sharedInterrupt skips installing the control's interrupt handler because the caller handles signals
+}
+
+// evaluate runs the evaluation as described by Evaluate, adjusted by the provided options.
+// The assessments are pulled from a SliceProvider over Assessments in execution order, followed by those of
+// opts.provider, which are appended to Assessments as they are pulled.
+func (c *ControlEvaluation) evaluate(ctx context.Context, targetData interface{}, userApplicability []string, changesAllowed bool, opts evaluateOptions) error {
+	c.Complete = false
+	userApplicability = c.targetApplicability(targetData, userApplicability)
+	if len(c.Assessments) == 0 && opts.provider == nil {
 		c.Result = NeedsReview
-		return
+		return ErrNoAssessments
 	}
-	c.closeHandler()
-	for _, assessment := range c.Assessments {
-		var applicabile bool
-		for _, aa := range assessment.Applicability {
-			for _, ua := range userApplicability {
-				if aa == ua {
-					applicabile = true
-					break
-				}
+	if err := c.Validate(); err != nil {
+		c.Result = Unknown
+		c.Message = err.Error()
+		return err
+	}
+	if err := validateApplicability(userApplicability); err != nil {
+		err = fmt.Errorf("invalid target applicability: %w", err)
+		c.Result = Unknown
+		c.Message = err.Error()
+		return err
+	}
+	ordered, err := executionOrder(c.Assessments)
+	if err != nil {
+		c.Result = Unknown
+		c.Message = err.Error()
+		return err
+	}
+	var provider AssessmentProvider = NewSliceProvider(ordered)
+	if opts.provider != nil {
+		provider = chainProviders(provider, opts.provider)
+	}
+	if !opts.sharedInterrupt {
+		stop := c.closeHandler()
+		defer stop()
+	}
+	if c.Teardown != nil {
+		defer c.Teardown()
+	}
+	if c.Setup != nil {
+		if err := c.Setup(); err != nil {
+			err = fmt.Errorf("setup failed: %w", err)
+			c.Result = Unknown
+			c.Message = err.Error()
+			return err
+		}
+	}
+	// Cleanup is deferred so that applied changes are reverted even if a step panics
+	defer c.Cleanup()
+	if opts.resume {
+		// the results of completed assessments are folded in again below
+		c.Result = NotRun
+		c.Message = ""
+	}
+	ctx = c.withMetadata(ctx)
+	applicable := make(map[*Assessment]bool)
+	index := make(map[*Assessment]int)
+	completed := make(map[*Assessment]bool)
+	c.Applicability_Summary = ApplicabilitySummary{Provided: append([]string(nil), userApplicability...)}
+	classify := func(i int, assessment *Assessment) {
+		index[assessment] = i
+		completed[assessment] = opts.resume && assessment.Result != NotRun && !assessment.Interrupted
+		assessment.Matched_Applicability, applicable[assessment] = assessment.matchApplicabilityWithMatcher(userApplicability, c.settingsFor(assessment).matcher)
+		c.Applicability_Summary.Assessments++
+		if applicable[assessment] {
+			c.Applicability_Summary.Matched++
+			return
+		}
+		c.Applicability_Summary.Skipped++
+		if c.Prefilter_Applicability {
+			assessment.Result = NotApplicable
+		}
+	}
+	for i, assessment := range c.Assessments {
+		classify(i, assessment)
+	}
+	var errs []error
+	exhausted := false
+	for {
+		if err := ctx.Err(); err != nil {
+			c.Result = UpdateAggregateResult(c.Result, Unknown)
+			c.Message = fmt.Sprintf("evaluation cancelled: %v", err)
+			errs = append(errs, err)
+			break
+		}
+		assessment, ok := provider.Next()
+		if !ok {
+			exhausted = true
+			break
+		}
+		if _, known := index[assessment]; !known {
+			if _, duplicate := c.GetAssessment(assessment.Requirement_Id); duplicate {
+				err := fmt.Errorf("duplicate requirement id: %s", assessment.Requirement_Id)
+				c.Result = Unknown
+				c.Message = err.Error()
+				errs = append(errs, err)
+				break
 			}
+			c.Assessments = append(c.Assessments, assessment)
+			classify(len(c.Assessments)-1, assessment)
 		}
-		if applicabile {
-			result := assessment.Run(targetData, changesAllowed)
-			c.Result = UpdateAggregateResult(c.Result, result)
+		if !applicable[assessment] {
+			continue
+		}
+		if completed[assessment] {
+			c.Result = UpdateAggregateResult(c.Result, assessment.Result)
 			c.Message = assessment.Message
-			if c.Result == Failed {
+			if c.shouldHalt(assessment.Result) {
+				break
+			}
+			continue
+		}
+		if dependency, unmet := c.unmetDependency(assessment); unmet {
+			assessment.Result = NotApplicable
+			assessment.Message = fmt.Sprintf("skipped because dependency %s did not pass", dependency)
+			continue
+		}
+		if opts.resume && assessment.Interrupted {
+			assessment.Reset()
+		}
+		result, err := c.runAssessment(ctx, assessment, targetData, changesAllowed)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if opts.onProgress != nil {
+			opts.onProgress(AssessmentProgress{Index: index[assessment], Requirement_Id: assessment.Requirement_Id, Result: result})
+		}
+		c.Result = UpdateAggregateResult(c.Result, result)
+		c.Message = assessment.Message
+		if c.shouldHalt(result) {
+			break
+		}
+	}
+	if exhausted && len(c.Assessments) == 0 {
+		c.Result = NeedsReview
+		return ErrNoAssessments
+	}
+	c.Complete = exhausted && allRun(applicable)
+	if c.Complete && c.Result == NotRun {
+		// nothing applied to the target, which is distinct from an evaluation that never ran
+		c.Result = NotApplicable
+	}
+	c.formatMessage()
+	return errors.Join(errs...)
+}
+
+// runAssessment runs a single assessment between the Before_Assessment and After_Assessment hooks,
+// reporting it to the metrics sink and returning an error if the assessment could not be run
+func (c *ControlEvaluation) runAssessment(ctx context.Context, assessment *Assessment, targetData interface{}, changesAllowed bool) (Result, error) {
+	if c.Before_Assessment != nil {
+		c.Before_Assessment(assessment)
+	}
+	startTime := assessment.clock().Now()
+	result, err := assessment.runWithSettings(ctx, targetData, changesAllowed, c.settingsFor(assessment))
+	if err != nil {
+		err = fmt.Errorf("assessment %s could not be run: %w", assessment.Requirement_Id, err)
+	}
+	c.recordAssessment(assessment, assessment.clock().Now().Sub(startTime))
+	if c.After_Assessment != nil {
+		c.After_Assessment(assessment)
+	}
+	return result, err
+}
+
+// recordAssessment reports the outcome of a completed assessment to the metrics sink, if one is set
+func (c *ControlEvaluation) recordAssessment(assessment *Assessment, duration time.Duration) {
+	if c.Metrics == nil {
+		return
+	}
+	c.Metrics.IncResult(assessment.Result)
+	c.Metrics.ObserveDuration(duration)
+	for _, change := range assessment.allChanges() {
+		if change.Applied {
+			c.Metrics.IncChangesApplied()
+		}
+	}
+}
+
+// formatMessage replaces the Message using the Message_Formatter, if one is set
+func (c *ControlEvaluation) formatMessage() {
+	if c.Message_Formatter != nil {
+		c.Message = c.Message_Formatter(c.Assessments)
+	}
+}
+
+// allRun returns true if every applicable assessment has a Result other than NotRun
+func allRun(applicable map[*Assessment]bool) bool {
+	for assessment, ok := range applicable {
+		if ok && assessment.Result == NotRun {
+			return false
+		}
+	}
+	return true
+}
+
+// SetLabel sets a key/value label on the ControlEvaluation
+func (c *ControlEvaluation) SetLabel(key, value string) {
+	if c.Labels == nil {
+		c.Labels = make(map[string]string)
+	}
+	c.Labels[key] = value
+}
+
+// FilterByResult returns a report of the ControlEvaluation containing only the assessments with one of the provided results,
+// such as Failed, NeedsReview, and Unknown for a report of actionable items. The control's serialized fields, including its
+// Result, are preserved, and the kept assessments are copied along with their results, so the report shares no state with
+// the original. Execution hooks such as Setup and Metrics are not carried over, since the report is not meant to be evaluated.
+func (c *ControlEvaluation) FilterByResult(keep ...Result) *ControlEvaluation {
+	var kept []*Assessment
+	for _, assessment := range c.Assessments {
+		if slices.Contains(keep, assessment.Result) {
+			kept = append(kept, assessment.copy())
+		}
+	}
+	filtered := c.withAssessments(kept)
+	filtered.Result = c.Result
+	filtered.Message = c.Message
+	filtered.Corrupted_State = c.Corrupted_State
+	filtered.Complete = c.Complete
+	filtered.Applicability_Summary = c.Applicability_Summary
+	filtered.Applicability_Summary.Provided = slices.Clone(c.Applicability_Summary.Provided)
+	filtered.Cleanup_Errors = slices.Clone(c.Cleanup_Errors)
+	filtered.Cleanup_Error_Messages = slices.Clone(c.Cleanup_Error_Messages)
+	return filtered
+}
+
+// FilterByLabels returns the evaluations whose labels contain every key/value pair in the selector.
+// An empty selector matches every evaluation.
+func FilterByLabels(evals []*ControlEvaluation, selector map[string]string) (matched []*ControlEvaluation) {
+	for _, eval := range evals {
+		matches := true
+		for key, value := range selector {
+			if actual, ok := eval.Labels[key]; !ok || actual != value {
+				matches = false
 				break
 			}
 		}
+		if matches {
+			matched = append(matched, eval)
+		}
+	}
+	return
+}
+
+// FailedAssessments returns the assessments whose Result is Failed, in their original order.
+// Unlike FilterByResult, the assessments themselves are returned rather than a filtered copy of the control.
+func (c *ControlEvaluation) FailedAssessments() (failed []*Assessment) {
+	for _, assessment := range c.Assessments {
+		if assessment.Result == Failed {
+			failed = append(failed, assessment)
+		}
+	}
+	return
+}
+
+// AllFailedAssessments returns the failed assessments of every evaluation, as described by FailedAssessments
+func AllFailedAssessments(evals []*ControlEvaluation) (failed []*Assessment) {
+	for _, eval := range evals {
+		failed = append(failed, eval.FailedAssessments()...)
+	}
+	return
+}
+
+// Validate checks that the control evaluation can be run as intended.
+// It returns an error if any assessment is missing required fields or depends on a Requirement_Id
+// that is not in the evaluation, both of which match ErrMisconfigured, if two assessments
+// share a Requirement_Id, or if the assessment dependencies form a cycle.
+// If Exclusive_Change_Targets is set, it also returns the ChangeConflicts.
+func (c *ControlEvaluation) Validate() error {
+	var errs []error
+	seen := make(map[string]bool)
+	known := make(map[string]bool, len(c.Assessments))
+	for _, assessment := range c.Assessments {
+		known[assessment.Requirement_Id] = true
+	}
+	for _, assessment := range c.Assessments {
+		for _, dependency := range assessment.Depends_On {
+			if !known[dependency] {
+				errs = append(errs, misconfiguredError{err: fmt.Errorf("assessment %s depends on unknown requirement id: %s", assessment.Requirement_Id, dependency)})
+			}
+		}
+		if err := assessment.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("assessment %s is invalid: %w", assessment.Requirement_Id, err))
+		}
+		if assessment.Requirement_Id == "" {
+			continue
+		}
+		if seen[assessment.Requirement_Id] {
+			errs = append(errs, fmt.Errorf("duplicate requirement id: %s", assessment.Requirement_Id))
+		}
+		seen[assessment.Requirement_Id] = true
+	}
+	if _, err := executionOrder(c.Assessments); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Exclusive_Change_Targets {
+		errs = append(errs, c.ChangeConflicts()...)
+	}
+	return errors.Join(errs...)
+}
+
+// ChangeConflicts returns an error for each change that targets the same Target_Name as an earlier change
+// in the evaluation, including changes in sub-assessments. Reverting such changes independently may leave
+// the shared target in an unexpected state. Read-only changes never modify their target, so they are skipped.
+func (c *ControlEvaluation) ChangeConflicts() (conflicts []error) {
+	owners := make(map[string]string)
+	var visit func(assessment *Assessment)
+	visit = func(assessment *Assessment) {
+		for _, name := range assessment.changeNames() {
+			change := assessment.Changes[name]
+			if change.readOnly {
+				continue
+			}
+			owner := fmt.Sprintf("%s/%s", assessment.Requirement_Id, name)
+			target := change.Target_Name
+			if previous, ok := owners[target]; ok {
+				conflicts = append(conflicts, fmt.Errorf("changes %s and %s both target %s", previous, owner, target))
+				continue
+			}
+			owners[target] = owner
+		}
+		for _, child := range assessment.Sub_Assessments {
+			visit(child)
+		}
+	}
+	for _, assessment := range c.Assessments {
+		visit(assessment)
+	}
+	return
+}
+
+// unmetDependency returns the first requirement ID the assessment depends on that has not passed
+func (c *ControlEvaluation) unmetDependency(assessment *Assessment) (requirementId string, unmet bool) {
+	for _, dependency := range assessment.Depends_On {
+		if candidate, ok := c.GetAssessment(dependency); !ok || !candidate.Result.IsPass() {
+			return dependency, true
+		}
+	}
+	return "", false
+}
+
+// executionOrder orders the assessments so that each runs after the assessments it depends on,
+// otherwise preserving their original order. It returns an error if the dependencies form a cycle.
+func executionOrder(assessments []*Assessment) ([]*Assessment, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	byId := make(map[string]*Assessment)
+	for _, assessment := range assessments {
+		byId[assessment.Requirement_Id] = assessment
+	}
+	state := make(map[*Assessment]int)
+	ordered := make([]*Assessment, 0, len(assessments))
+
+	var visit func(assessment *Assessment) error
+	visit = func(assessment *Assessment) error {
+		switch state[assessment] {
+		case visiting:
+			return fmt.Errorf("dependency cycle detected at assessment %s", assessment.Requirement_Id)
+		case visited:
+			return nil
+		}
+		state[assessment] = visiting
+		for _, dependency := range assessment.Depends_On {
+			if prerequisite, ok := byId[dependency]; ok {
+				if err := visit(prerequisite); err != nil {
+					return err
+				}
+			}
+		}
+		state[assessment] = visited
+		ordered = append(ordered, assessment)
+		return nil
+	}
+	for _, assessment := range assessments {
+		if err := visit(assessment); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Merge combines the results of another evaluation of the same control into this one.
+// The other evaluation's assessments are appended, its Result is folded into the aggregate Result,
+// and the Corrupted_State is retained if either evaluation was left in a corrupted state.
+// An error is returned, leaving this evaluation unchanged, if other is nil, is for a different control,
+// or has an assessment with the same Requirement_Id as one in this evaluation. To merge evaluations of
+// the same assessments from several targets, give each target's assessments distinct requirement IDs first.
+func (c *ControlEvaluation) Merge(other *ControlEvaluation) error {
+	if other == nil {
+		return errors.New("cannot merge a nil evaluation")
+	}
+	if c.Control_Id != other.Control_Id {
+		return fmt.Errorf("cannot merge evaluations for different controls: %s and %s", c.Control_Id, other.Control_Id)
+	}
+	var duplicates []string
+	for _, assessment := range other.Assessments {
+		if _, ok := c.GetAssessment(assessment.Requirement_Id); ok {
+			duplicates = append(duplicates, assessment.Requirement_Id)
+		}
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("cannot merge evaluations with duplicate requirement ids: %s", strings.Join(duplicates, ", "))
+	}
+	c.Assessments = append(c.Assessments, other.Assessments...)
+	c.Result = UpdateAggregateResult(c.Result, other.Result)
+	c.Corrupted_State = c.Corrupted_State || other.Corrupted_State
+	c.Complete = c.Complete && other.Complete
+	c.addCleanupErrors(other.Cleanup_Errors...)
+	return nil
+}
+
+// Clone returns an independent copy of the ControlEvaluation with its execution state reset,
+// containing clones of each assessment as described by Assessment.Clone.
+func (c *ControlEvaluation) Clone() *ControlEvaluation {
+	assessments := make([]*Assessment, 0, len(c.Assessments))
+	for _, assessment := range c.Assessments {
+		assessments = append(assessments, assessment.Clone())
+	}
+	clone := c.withAssessments(assessments)
+	clone.Before_Assessment = c.Before_Assessment
+	clone.After_Assessment = c.After_Assessment
+	clone.Applicability_Matcher = c.Applicability_Matcher
+	clone.Interrupt_Handler = c.Interrupt_Handler
+	clone.Metrics = c.Metrics
+	clone.Applicability_Extractor = c.Applicability_Extractor
+	clone.Message_Formatter = c.Message_Formatter
+	clone.Halt_Predicate = c.Halt_Predicate
+	clone.Setup = c.Setup
+	clone.Teardown = c.Teardown
+	return clone
+}
+
+// withAssessments returns a new ControlEvaluation holding the provided assessments, with the identity and serialized
+// configuration of c but none of its results or execution hooks
+func (c *ControlEvaluation) withAssessments(assessments []*Assessment) *ControlEvaluation {
+	copied := &ControlEvaluation{
+		Name:                     c.Name,
+		Control_Id:               c.Control_Id,
+		Remediation_Guide:        c.Remediation_Guide,
+		Assessments:              assessments,
+		Labels:                   maps.Clone(c.Labels),
+		Require_Target_Data:      c.Require_Target_Data,
+		Exclusive_Change_Targets: c.Exclusive_Change_Targets,
+		Halt_On_Unknown:          c.Halt_On_Unknown,
+		Prefilter_Applicability:  c.Prefilter_Applicability,
+	}
+	if c.Revert_Policy != nil {
+		policy := *c.Revert_Policy
+		copied.Revert_Policy = &policy
+	}
+	return copied
+}
+
+// targetApplicability returns the provided applicability, or the applicability derived from the target data
+// by the Applicability_Extractor if none was provided
+func (c *ControlEvaluation) targetApplicability(targetData interface{}, userApplicability []string) []string {
+	if len(userApplicability) == 0 && c.Applicability_Extractor != nil {
+		return c.Applicability_Extractor(targetData)
+	}
+	return userApplicability
+}
+
+// settingsFor returns the settings the assessment runs with as part of the control,
+// with the control's settings filling in any that the assessment has not set itself
+func (c *ControlEvaluation) settingsFor(assessment *Assessment) runSettings {
+	settings := assessment.settings()
+	if settings.matcher == nil {
+		settings.matcher = c.Applicability_Matcher
+	}
+	settings.requireTargetData = settings.requireTargetData || c.Require_Target_Data
+	settings.haltOnUnknown = settings.haltOnUnknown || c.Halt_On_Unknown
+	if settings.haltPredicate == nil {
+		settings.haltPredicate = c.Halt_Predicate
+	}
+	return settings
+}
+
+// shouldHalt reports whether an assessment's result should stop the evaluation, using the Halt_Predicate if one is set.
+// By default only Failed halts, along with Unknown if Halt_On_Unknown is set.
+func (c *ControlEvaluation) shouldHalt(result Result) bool {
+	if c.Halt_Predicate != nil {
+		return c.Halt_Predicate(result)
 	}
-	c.Cleanup()
+	return result == Failed || (c.Halt_On_Unknown && result == Unknown)
 }
 
+// Cleanup reverts the changes made by each assessment, recording whether any failed to revert.
+// It is safe to call concurrently, such as from the interrupt handler while an evaluation is finishing;
+// concurrent calls are serialized so that each change is reverted at most once.
+// If a Revert_Policy is set, failed reverts are retried and cleanup continues or stops after a failure as it describes.
+// Each retry waits no longer than the policy's Max_Backoff, so that cleanup from the interrupt handler is not held up
+// by an unbounded backoff; use CleanupWithContext to also cap the total time spent.
 func (c *ControlEvaluation) Cleanup() {
+	c.cleanup(context.Background(), c.Revert_Policy)
+}
+
+// CleanupWithContext behaves like Cleanup, but stops attempting further reverts once the context is done,
+// as described by Assessment.RevertChangesWithContext. Any change left unreverted sets Corrupted_State
+// and is reported in Cleanup_Errors, and remains applied to its target until it is remediated.
+func (c *ControlEvaluation) CleanupWithContext(ctx context.Context) {
+	policy := c.Revert_Policy
+	if policy == nil {
+		policy = &RevertPolicy{}
+	}
+	c.cleanup(ctx, policy)
+}
+
+// cleanup reverts the changes of each assessment, using the policy if one is provided
+func (c *ControlEvaluation) cleanup(ctx context.Context, policy *RevertPolicy) {
+	c.cleanupMu.Lock()
+	defer c.cleanupMu.Unlock()
+	c.Cleanup_Errors = nil
+	c.Cleanup_Error_Messages = nil
+	for _, assessment := range c.Assessments {
+		revert := assessment.RevertChanges
+		if policy != nil {
+			revert = func() bool { return assessment.revertChangesWithPolicy(ctx, policy) }
+		}
+		if c.cleanupAssessment(assessment, revert) && policy != nil && policy.Stop_On_Failure {
+			break
+		}
+	}
+}
+
+// ForceCleanup behaves like Cleanup, but attempts to revert every registered change even if it was never
+// marked as applied. This is a safety-first option for apply functions that may change a target without
+// reporting it, and requires every revert function to be idempotent.
+func (c *ControlEvaluation) ForceCleanup() {
+	c.cleanupMu.Lock()
+	defer c.cleanupMu.Unlock()
+	c.Cleanup_Errors = nil
+	c.Cleanup_Error_Messages = nil
+	for _, assessment := range c.Assessments {
+		c.cleanupAssessment(assessment, assessment.ForceRevertAll)
+	}
+}
+
+// addCleanupErrors records errors in Cleanup_Errors, along with their messages in Cleanup_Error_Messages
+func (c *ControlEvaluation) addCleanupErrors(errs ...error) {
+	for _, err := range errs {
+		c.Cleanup_Errors = append(c.Cleanup_Errors, err)
+		c.Cleanup_Error_Messages = append(c.Cleanup_Error_Messages, err.Error())
+	}
+}
+
+// cleanupAssessment reverts an assessment's changes with the provided revert method,
+// recording any corruption and reporting the outcome of each applied change to the metrics sink.
+// It returns true if any change could not be reverted.
+func (c *ControlEvaluation) cleanupAssessment(assessment *Assessment, revert func() (corrupted bool)) (corrupted bool) {
+	var pending []*Change
+	for _, change := range assessment.allChanges() {
+		if change.Applied && !change.Reverted {
+			pending = append(pending, change)
+		}
+	}
+	if corrupted = revert(); corrupted {
+		c.Corrupted_State = true
+		c.addCleanupErrors(assessment.revertErrors()...)
+	}
+	if c.Metrics == nil {
+		return
+	}
+	for _, change := range pending {
+		if change.Reverted {
+			c.Metrics.IncChangesReverted()
+		} else {
+			c.Metrics.IncRevertFailures()
+		}
+	}
+	return
+}
+
+// ChangeSummary tallies the changes of every assessment, including those of Sub_Assessments,
+// by whether they were applied and whether they were reverted
+func (c *ControlEvaluation) ChangeSummary() (counts ChangeCounts) {
 	for _, assessment := range c.Assessments {
-		corrupted := assessment.RevertChanges()
-		if corrupted {
-			c.Corrupted_State = true
+		for _, change := range assessment.allChanges() {
+			switch {
+			case !change.Applied:
+				if change.Error != nil {
+					counts.Apply_Failures++
+				}
+				continue
+			case change.Error != nil:
+				counts.Revert_Failures++
+			case change.Reverted:
+				counts.Reverted++
+			default:
+				counts.Still_Applied++
+			}
+			counts.Applied++
 		}
 	}
+	return
+}
+
+// InterruptHandler configures how a ControlEvaluation responds to termination signals received while it is running
+type InterruptHandler struct {
+	Signals  []os.Signal     // Signals is the set of signals to handle; defaults to os.Interrupt and syscall.SIGTERM
+	OnSignal func(os.Signal) // OnSignal is called with the received signal after cleanup; defaults to exiting with status 0
+}
+
+// ReraiseSignal restores the default behavior for the signal and sends it to the current process,
+// allowing a parent process to observe how this process was terminated. It may be used as an OnSignal action.
+func ReraiseSignal(sig os.Signal) {
+	signal.Reset(sig)
+	process, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = process.Signal(sig)
+	}
+	if err != nil {
+		log.Printf("failed to re-raise signal %v: %v", sig, err)
+		os.Exit(1)
+	}
 }
 
 // CloseHandler creates a 'listener' on a new goroutine which will notify the
 // program if it receives an interrupt from the operating system.
 // If an interrupt is received, this will attempt to revert any changes
 // made by the terminated ControlEvaluation.
-func (c *ControlEvaluation) closeHandler() {
+// The returned function stops the listener once the evaluation is complete.
+func (c *ControlEvaluation) closeHandler() (stop func()) {
+	return notifyInterrupt(c.Interrupt_Handler, c.Cleanup)
+}
+
+// notifyInterrupt starts listening for the handler's signals on a new goroutine, calling cleanup and then the
+// handler's OnSignal action if one is received. The returned function stops the listener.
+func notifyInterrupt(handler *InterruptHandler, cleanup func()) (stop func()) {
 	// Ref: https://golangcode.com/handle-ctrl-c-exit-in-terminal/
+	if handler == nil {
+		handler = &InterruptHandler{}
+	}
+	signals := handler.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
 	channel := make(chan os.Signal, 1)
-	signal.Notify(channel, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-channel
+	done := make(chan struct{})
+	signal.Notify(channel, signals...)
+	go handleInterrupt(channel, done, cleanup, handler.OnSignal)
+	return func() {
+		signal.Stop(channel)
+		close(done)
+	}
+}
+
+// handleInterrupt waits for a signal, reverting any changes with cleanup and then calling onSignal when one is received.
+// It returns without taking any action once done is closed.
+func handleInterrupt(channel <-chan os.Signal, done <-chan struct{}, cleanup func(), onSignal func(os.Signal)) {
+	select {
+	case sig := <-channel:
 		log.Print("\n*****\nUnexpected termination. Attempting to revert changes made by the active ControlEvaluation. Do not interrupt this process.\n*****\n")
-		c.Cleanup()
-		os.Exit(0)
-	}()
+		cleanup()
+		if onSignal == nil {
+			os.Exit(0)
+		}
+		onSignal(sig)
+	case <-done:
+	}
 }
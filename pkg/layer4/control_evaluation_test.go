@@ -1,6 +1,20 @@
 package layer4
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
 
 var controlEvaluationTestData = []struct {
 	testName          string
@@ -150,3 +164,1233 @@ func TestAddAssesment(t *testing.T) {
 	}
 
 }
+
+func TestAssessmentHooks(t *testing.T) {
+	first := &Assessment{
+		Requirement_Id: "first",
+		Description:    "first assessment",
+		Applicability:  testingApplicability,
+		Steps:          []AssessmentStep{passingAssessmentStep},
+	}
+	second := &Assessment{
+		Requirement_Id: "second",
+		Description:    "second assessment",
+		Applicability:  testingApplicability,
+		Steps:          []AssessmentStep{needsReviewAssessmentStep},
+	}
+	c := &ControlEvaluation{
+		Assessments: []*Assessment{first, second},
+	}
+
+	var calls []string
+	var seen []*Assessment
+	c.Before_Assessment = func(a *Assessment) {
+		if a.Result != NotRun {
+			t.Errorf("Expected %s to have Result %v before running, but it was %v", a.Requirement_Id, NotRun, a.Result)
+		}
+		calls = append(calls, "before:"+a.Requirement_Id)
+		seen = append(seen, a)
+	}
+	c.After_Assessment = func(a *Assessment) {
+		if a.Result == NotRun {
+			t.Errorf("Expected %s to have a Result set after running, but it was %v", a.Requirement_Id, a.Result)
+		}
+		calls = append(calls, "after:"+a.Requirement_Id)
+		seen = append(seen, a)
+	}
+	c.Evaluate(nil, testingApplicability, false)
+
+	expectedCalls := []string{"before:first", "after:first", "before:second", "after:second"}
+	expectedSeen := []*Assessment{first, first, second, second}
+	if len(calls) != len(expectedCalls) {
+		t.Fatalf("Expected %d hook calls, but got %d: %v", len(expectedCalls), len(calls), calls)
+	}
+	for i := range expectedCalls {
+		if calls[i] != expectedCalls[i] {
+			t.Errorf("Expected hook call %d to be %q, but it was %q", i, expectedCalls[i], calls[i])
+		}
+		if seen[i] != expectedSeen[i] {
+			t.Errorf("Expected hook call %d to receive the %s assessment pointer", i, expectedSeen[i].Requirement_Id)
+		}
+	}
+}
+
+func TestApplicableAssessments(t *testing.T) {
+	applicable := &Assessment{
+		Requirement_Id: "applicable",
+		Description:    "applicable assessment",
+		Applicability:  testingApplicability,
+		Steps:          []AssessmentStep{passingAssessmentStep},
+	}
+	inapplicable := &Assessment{
+		Requirement_Id: "inapplicable",
+		Description:    "inapplicable assessment",
+		Applicability:  []string{"other-applicability"},
+		Steps:          []AssessmentStep{failingAssessmentStep},
+	}
+	c := &ControlEvaluation{
+		Assessments: []*Assessment{inapplicable, applicable},
+	}
+
+	subset := c.ApplicableAssessments(testingApplicability)
+	if len(subset) != 1 || subset[0] != applicable {
+		t.Fatalf("Expected only the applicable assessment to be returned, but got %d assessments", len(subset))
+	}
+
+	c.Evaluate(nil, testingApplicability, false)
+	if inapplicable.Steps_Executed != 0 {
+		t.Errorf("Expected inapplicable assessment to be skipped, but it executed %d steps", inapplicable.Steps_Executed)
+	}
+	if inapplicable.Result != NotRun {
+		t.Errorf("Expected inapplicable assessment Result to be left %v by default, but it was %v", NotRun, inapplicable.Result)
+	}
+	if c.Result != Passed || !c.Complete {
+		t.Errorf("Expected Result to be a complete %v, but it was %v", Passed, c.Result)
+	}
+	if c.Applicability_Summary.Skipped != 1 {
+		t.Errorf("Expected 1 skipped assessment, but got %d", c.Applicability_Summary.Skipped)
+	}
+
+	prefiltered := c.Clone()
+	prefiltered.Prefilter_Applicability = true
+	prefiltered.Evaluate(nil, testingApplicability, false)
+	if prefiltered.Assessments[0].Result != NotApplicable || prefiltered.Assessments[0].Steps_Executed != 0 {
+		t.Errorf("Expected Prefilter_Applicability to mark the inapplicable assessment %v without running it, but it was %v", NotApplicable, prefiltered.Assessments[0].Result)
+	}
+	if prefiltered.Result != Passed || !prefiltered.Complete {
+		t.Errorf("Expected Result to be a complete %v, but it was %v", Passed, prefiltered.Result)
+	}
+}
+
+func TestTryEvaluate(t *testing.T) {
+	panickingStep := func(interface{}, map[string]*Change) (Result, string) {
+		panic("unexpected")
+	}
+	tests := []struct {
+		testName       string
+		control        *ControlEvaluation
+		expectedResult Result
+		expectedError  bool
+	}{
+		{
+			testName:       "No assessments",
+			control:        &ControlEvaluation{},
+			expectedResult: NeedsReview,
+			expectedError:  true,
+		},
+		{
+			testName: "Assessment fails precheck",
+			control: &ControlEvaluation{
+				Assessments: []*Assessment{
+					{Requirement_Id: "no-steps", Description: "no steps", Applicability: testingApplicability},
+				},
+			},
+			expectedResult: Unknown,
+			expectedError:  true,
+		},
+		{
+			testName: "Step panics",
+			control: &ControlEvaluation{
+				Assessments: []*Assessment{
+					{Requirement_Id: "panics", Description: "panics", Applicability: testingApplicability, Steps: []AssessmentStep{panickingStep}},
+				},
+			},
+			expectedResult: Unknown,
+			expectedError:  true,
+		},
+		{
+			testName: "Clean evaluation that fails",
+			control: &ControlEvaluation{
+				Assessments: []*Assessment{
+					{Requirement_Id: "fails", Description: "fails", Applicability: testingApplicability, Steps: []AssessmentStep{failingAssessmentStep}},
+				},
+			},
+			expectedResult: Failed,
+			expectedError:  false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			err := test.control.TryEvaluate(nil, testingApplicability, false)
+			if test.expectedError && err == nil {
+				t.Error("Expected an error, but got nil")
+			}
+			if !test.expectedError && err != nil {
+				t.Errorf("Expected no error, but got %v", err)
+			}
+			if test.control.Result != test.expectedResult {
+				t.Errorf("Expected Result to be %v, but it was %v", test.expectedResult, test.control.Result)
+			}
+		})
+	}
+}
+
+func TestEvaluateMultipleApplicabilities(t *testing.T) {
+	prod := &Assessment{
+		Requirement_Id: "prod",
+		Description:    "applies to prod",
+		Applicability:  []string{"prod"},
+		Steps:          []AssessmentStep{passingAssessmentStep},
+	}
+	aws := &Assessment{
+		Requirement_Id: "aws",
+		Description:    "applies to aws",
+		Applicability:  []string{"aws"},
+		Steps:          []AssessmentStep{needsReviewAssessmentStep},
+	}
+	gcp := &Assessment{
+		Requirement_Id: "gcp",
+		Description:    "applies to gcp",
+		Applicability:  []string{"gcp"},
+		Steps:          []AssessmentStep{failingAssessmentStep},
+	}
+	c := &ControlEvaluation{
+		Assessments: []*Assessment{prod, aws, gcp},
+	}
+	c.Evaluate(nil, []string{"prod", "aws"}, false)
+
+	if prod.Steps_Executed != 1 || aws.Steps_Executed != 1 {
+		t.Errorf("Expected both the prod and aws assessments to run, but they executed %d and %d steps", prod.Steps_Executed, aws.Steps_Executed)
+	}
+	if gcp.Steps_Executed != 0 {
+		t.Errorf("Expected the gcp assessment to be skipped, but it executed %d steps", gcp.Steps_Executed)
+	}
+	if c.Result != NeedsReview {
+		t.Errorf("Expected Result to be %v, but it was %v", NeedsReview, c.Result)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		testName          string
+		control           *ControlEvaluation
+		other             *ControlEvaluation
+		expectedError     bool
+		expectedResult    Result
+		expectedCorrupted bool
+	}{
+		{
+			testName: "Matching control IDs",
+			control: &ControlEvaluation{
+				Control_Id:  "CTRL-01",
+				Result:      Passed,
+				Assessments: []*Assessment{{Requirement_Id: "first"}},
+			},
+			other: &ControlEvaluation{
+				Control_Id:      "CTRL-01",
+				Result:          NeedsReview,
+				Corrupted_State: true,
+				Assessments:     []*Assessment{{Requirement_Id: "second"}},
+			},
+			expectedResult:    NeedsReview,
+			expectedCorrupted: true,
+		},
+		{
+			testName: "Mismatching control IDs",
+			control: &ControlEvaluation{
+				Control_Id:  "CTRL-01",
+				Result:      Passed,
+				Assessments: []*Assessment{{Requirement_Id: "first"}},
+			},
+			other: &ControlEvaluation{
+				Control_Id:  "CTRL-02",
+				Result:      Failed,
+				Assessments: []*Assessment{{Requirement_Id: "second"}},
+			},
+			expectedError:  true,
+			expectedResult: Passed,
+		},
+		{
+			testName: "Duplicate requirement IDs",
+			control: &ControlEvaluation{
+				Control_Id:  "CTRL-01",
+				Result:      Passed,
+				Assessments: []*Assessment{{Requirement_Id: "first"}},
+			},
+			other: &ControlEvaluation{
+				Control_Id:  "CTRL-01",
+				Result:      Failed,
+				Assessments: []*Assessment{{Requirement_Id: "first"}},
+			},
+			expectedError:  true,
+			expectedResult: Passed,
+		},
+		{
+			testName: "Nil evaluation",
+			control: &ControlEvaluation{
+				Control_Id:  "CTRL-01",
+				Result:      Passed,
+				Assessments: []*Assessment{{Requirement_Id: "first"}},
+			},
+			expectedError:  true,
+			expectedResult: Passed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			err := test.control.Merge(test.other)
+			if test.expectedError {
+				if err == nil {
+					t.Error("Expected an error, but got nil")
+				}
+				if len(test.control.Assessments) != 1 {
+					t.Errorf("Expected assessments to be unchanged after a failed merge, but found %d", len(test.control.Assessments))
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error, but got %v", err)
+				}
+				if len(test.control.Assessments) != 2 {
+					t.Errorf("Expected 2 assessments after merging, but found %d", len(test.control.Assessments))
+				}
+			}
+			if test.control.Result != test.expectedResult {
+				t.Errorf("Expected Result to be %v, but it was %v", test.expectedResult, test.control.Result)
+			}
+			if test.control.Corrupted_State != test.expectedCorrupted {
+				t.Errorf("Expected Corrupted_State to be %v, but it was %v", test.expectedCorrupted, test.control.Corrupted_State)
+			}
+		})
+	}
+}
+
+func TestDependsOn(t *testing.T) {
+	newAssessment := func(id string, step AssessmentStep, dependsOn ...string) *Assessment {
+		return &Assessment{
+			Requirement_Id: id,
+			Description:    id,
+			Applicability:  testingApplicability,
+			Steps:          []AssessmentStep{step},
+			Depends_On:     dependsOn,
+		}
+	}
+
+	t.Run("Satisfied dependency", func(t *testing.T) {
+		dependent := newAssessment("encryption", passingAssessmentStep, "exists")
+		prerequisite := newAssessment("exists", passingAssessmentStep)
+		c := &ControlEvaluation{Assessments: []*Assessment{dependent, prerequisite}}
+
+		var order []string
+		c.Before_Assessment = func(a *Assessment) { order = append(order, a.Requirement_Id) }
+		if err := c.TryEvaluate(nil, testingApplicability, false); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if len(order) != 2 || order[0] != "exists" || order[1] != "encryption" {
+			t.Errorf("Expected the prerequisite to run first, but the order was %v", order)
+		}
+		if dependent.Result != Passed {
+			t.Errorf("Expected the dependent assessment to pass, but it was %v", dependent.Result)
+		}
+	})
+
+	t.Run("Unsatisfied dependency", func(t *testing.T) {
+		prerequisite := newAssessment("exists", needsReviewAssessmentStep)
+		dependent := newAssessment("encryption", passingAssessmentStep, "exists")
+		c := &ControlEvaluation{Assessments: []*Assessment{prerequisite, dependent}}
+		c.Evaluate(nil, testingApplicability, false)
+
+		if dependent.Steps_Executed != 0 {
+			t.Errorf("Expected the dependent assessment to be skipped, but it executed %d steps", dependent.Steps_Executed)
+		}
+		if dependent.Result != NotApplicable {
+			t.Errorf("Expected the dependent assessment to be %v, but it was %v", NotApplicable, dependent.Result)
+		}
+		if c.Result != NeedsReview {
+			t.Errorf("Expected Result to be %v, but it was %v", NeedsReview, c.Result)
+		}
+	})
+
+	t.Run("Cyclic dependency", func(t *testing.T) {
+		first := newAssessment("first", passingAssessmentStep, "second")
+		second := newAssessment("second", passingAssessmentStep, "first")
+		c := &ControlEvaluation{Assessments: []*Assessment{first, second}}
+
+		if err := c.TryEvaluate(nil, testingApplicability, false); err == nil {
+			t.Error("Expected a dependency cycle error, but got nil")
+		}
+		if c.Result != Unknown {
+			t.Errorf("Expected Result to be %v, but it was %v", Unknown, c.Result)
+		}
+		if first.Steps_Executed != 0 || second.Steps_Executed != 0 {
+			t.Errorf("Expected no assessments to run when dependencies are cyclic")
+		}
+	})
+}
+
+func TestControlEvaluationClone(t *testing.T) {
+	original := &ControlEvaluation{
+		Control_Id: "CTRL-01",
+		Assessments: []*Assessment{{
+			Requirement_Id: "original",
+			Description:    "original assessment",
+			Applicability:  testingApplicability,
+			Steps:          []AssessmentStep{failingAssessmentStep},
+		}},
+	}
+	clone := original.Clone()
+	clone.Evaluate(nil, testingApplicability, false)
+
+	if clone.Control_Id != original.Control_Id {
+		t.Errorf("Expected clone to keep Control_Id %s, but it was %s", original.Control_Id, clone.Control_Id)
+	}
+	if clone.Result != Failed {
+		t.Errorf("Expected clone Result to be %v, but it was %v", Failed, clone.Result)
+	}
+	if original.Result != NotRun || original.Assessments[0].Result != NotRun {
+		t.Errorf("Expected original to be unaffected by evaluating the clone, but its Result was %v", original.Result)
+	}
+}
+
+func TestEvaluateStream(t *testing.T) {
+	newAssessment := func(id string, step AssessmentStep, applicability []string) *Assessment {
+		return &Assessment{
+			Requirement_Id: id,
+			Description:    id,
+			Applicability:  applicability,
+			Steps:          []AssessmentStep{step},
+		}
+	}
+	c := &ControlEvaluation{
+		Assessments: []*Assessment{
+			newAssessment("first", passingAssessmentStep, testingApplicability),
+			newAssessment("skipped", passingAssessmentStep, []string{"other-applicability"}),
+			newAssessment("second", needsReviewAssessmentStep, testingApplicability),
+			newAssessment("third", failingAssessmentStep, testingApplicability),
+			newAssessment("halted", passingAssessmentStep, testingApplicability),
+		},
+	}
+
+	var events []AssessmentProgress
+	for event := range c.EvaluateStream(nil, testingApplicability, false) {
+		events = append(events, event)
+	}
+
+	expected := []AssessmentProgress{
+		{Index: 0, Requirement_Id: "first", Result: Passed},
+		{Index: 2, Requirement_Id: "second", Result: NeedsReview},
+		{Index: 3, Requirement_Id: "third", Result: Failed},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d progress events, but got %d: %v", len(expected), len(events), events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("Expected event %d to be %v, but it was %v", i, expected[i], events[i])
+		}
+	}
+	if c.Result != Failed {
+		t.Errorf("Expected Result to be %v once the stream closed, but it was %v", Failed, c.Result)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	newAssessment := func(id string) *Assessment {
+		return &Assessment{
+			Requirement_Id: id,
+			Description:    id,
+			Applicability:  testingApplicability,
+			Steps:          []AssessmentStep{passingAssessmentStep},
+		}
+	}
+	withDependency := func(id, dependency string) *Assessment {
+		a := newAssessment(id)
+		a.Depends_On = []string{dependency}
+		return a
+	}
+	tests := []struct {
+		testName      string
+		assessments   []*Assessment
+		expectedError bool
+		misconfigured bool
+	}{
+		{
+			testName:    "Clean control",
+			assessments: []*Assessment{newAssessment("first"), newAssessment("second")},
+		},
+		{
+			testName:      "Duplicate requirement IDs",
+			assessments:   []*Assessment{newAssessment("first"), newAssessment("first")},
+			expectedError: true,
+		},
+		{
+			testName:      "Assessment missing required fields",
+			assessments:   []*Assessment{newAssessment("first"), {Requirement_Id: "second"}},
+			expectedError: true,
+			misconfigured: true,
+		},
+		{
+			testName:    "Known dependency",
+			assessments: []*Assessment{withDependency("second", "first"), newAssessment("first")},
+		},
+		{
+			testName:      "Unknown dependency",
+			assessments:   []*Assessment{newAssessment("first"), withDependency("second", "frist")},
+			expectedError: true,
+			misconfigured: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			c := &ControlEvaluation{Assessments: test.assessments}
+			err := c.Validate()
+			if test.expectedError && err == nil {
+				t.Error("Expected an error, but got nil")
+			}
+			if !test.expectedError && err != nil {
+				t.Errorf("Expected no error, but got %v", err)
+			}
+			if errors.Is(err, ErrMisconfigured) != test.misconfigured {
+				t.Errorf("Expected the error to match ErrMisconfigured: %t, but got %v", test.misconfigured, err)
+			}
+
+			c.Evaluate(nil, testingApplicability, false)
+			if test.expectedError {
+				if c.Result != Unknown || c.Message != err.Error() {
+					t.Errorf("Expected an invalid control to be Unknown with the validation message, but got %v: %s", c.Result, c.Message)
+				}
+				if test.assessments[0].Steps_Executed != 0 {
+					t.Errorf("Expected no assessments to run for an invalid control")
+				}
+			} else if c.Result != Passed {
+				t.Errorf("Expected Result to be %v, but it was %v", Passed, c.Result)
+			}
+		})
+	}
+}
+
+func TestConcurrentCleanup(t *testing.T) {
+	var reverts int32
+	a := &Assessment{}
+	a.NewChange("change", "target", "description", nil, goodApplyFunc, func() error {
+		atomic.AddInt32(&reverts, 1)
+		return nil
+	})
+	a.Changes["change"].Apply()
+	c := &ControlEvaluation{Assessments: []*Assessment{a}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Cleanup()
+		}()
+	}
+	wg.Wait()
+
+	if reverts != 1 {
+		t.Errorf("Expected the change to be reverted exactly once, but it was reverted %d times", reverts)
+	}
+	if c.Corrupted_State {
+		t.Errorf("Expected Corrupted_State to be false after concurrent cleanup")
+	}
+}
+
+func TestCleanupErrors(t *testing.T) {
+	revertErr := errors.New("permission denied")
+	a := &Assessment{}
+	a.NewChange("change", "bucket-policy", "description", nil, goodApplyFunc, func() error {
+		return revertErr
+	})
+	a.Changes["change"].Apply()
+	c := &ControlEvaluation{Assessments: []*Assessment{a}}
+
+	c.Cleanup()
+
+	if !c.Corrupted_State {
+		t.Errorf("Expected Corrupted_State to be true after a failed revert")
+	}
+	if len(c.Cleanup_Errors) != 1 {
+		t.Fatalf("Expected 1 cleanup error, but got %d", len(c.Cleanup_Errors))
+	}
+	if !errors.Is(c.Cleanup_Errors[0], revertErr) {
+		t.Errorf("Expected the cleanup error to wrap the revert error, but got %v", c.Cleanup_Errors[0])
+	}
+	if !strings.Contains(c.Cleanup_Errors[0].Error(), "bucket-policy") {
+		t.Errorf("Expected the cleanup error to name the target, but got %v", c.Cleanup_Errors[0])
+	}
+
+	c.Cleanup()
+	if len(c.Cleanup_Errors) != 1 {
+		t.Errorf("Expected repeated cleanup not to duplicate errors, but got %d", len(c.Cleanup_Errors))
+	}
+}
+
+func TestCleanupErrorsRoundTrip(t *testing.T) {
+	c := &ControlEvaluation{Name: "cleanup", Control_Id: "cleanup"}
+	a := c.AddAssessment("cleanup", "failed revert", testingApplicability, []AssessmentStep{passingAssessmentStep})
+	a.NewChange("change", "bucket-policy", "description", nil, goodApplyFunc, badRevertFunc)
+	a.Changes["change"].Apply()
+	c.Evaluate(nil, testingApplicability, true)
+	if !c.Corrupted_State || len(c.Cleanup_Errors) != 1 {
+		t.Fatalf("Expected a failed revert, but got %v", c.Cleanup_Errors)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "{}") {
+		t.Errorf("Expected the errors to be serialized as messages, but got %s", data)
+	}
+	var decoded ControlEvaluation
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected the control to unmarshal, but got %v", err)
+	}
+	if len(decoded.Cleanup_Error_Messages) != 1 || decoded.Cleanup_Error_Messages[0] != c.Cleanup_Errors[0].Error() {
+		t.Errorf("Expected the cleanup error message to survive a round trip, but got %v", decoded.Cleanup_Error_Messages)
+	}
+	change := decoded.Assessments[0].Changes["change"]
+	if change.Error_Message != c.Assessments[0].Changes["change"].Error.Error() {
+		t.Errorf("Expected the change error message to survive a round trip, but got %q", change.Error_Message)
+	}
+	if decoded.Assessments[0].Steps[0].String() != AssessmentStep(passingAssessmentStep).String() {
+		t.Errorf("Expected the step name to be kept, but got %s", decoded.Assessments[0].Steps[0])
+	}
+
+	t.Run("Unregistered step", func(t *testing.T) {
+		data := []byte(`{"steps":["example.com/unregistered.step"],"context-steps":["example.com/unregistered.contextStep"]}`)
+		var decoded Assessment
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Expected a step from another process to decode, but got %v", err)
+		}
+		if decoded.Steps[0].String() != "example.com/unregistered.step" || decoded.Context_Steps[0].String() != "example.com/unregistered.contextStep" {
+			t.Errorf("Expected the step names to be kept for re-serialization, but got %s and %s", decoded.Steps[0], decoded.Context_Steps[0])
+		}
+		decoded.Requirement_Id, decoded.Description, decoded.Applicability = "decoded", "decoded", testingApplicability
+		decoded.Halt_Predicate = func(Result) bool { return false }
+		if result := decoded.Run(nil, false); result != Unknown || len(decoded.Step_Errors) != 1 {
+			t.Errorf("Expected a decoded step to report Unknown when run, but got %s with errors %v", result, decoded.Step_Errors)
+		}
+	})
+}
+
+func TestHandleInterrupt(t *testing.T) {
+	t.Run("Signal received", func(t *testing.T) {
+		a := &Assessment{}
+		a.NewChange("change", "target", "description", nil, goodApplyFunc, goodRevertFunc)
+		a.Changes["change"].Apply()
+		c := &ControlEvaluation{Assessments: []*Assessment{a}}
+
+		channel := make(chan os.Signal, 1)
+		channel <- syscall.SIGHUP
+		var received os.Signal
+		handleInterrupt(channel, make(chan struct{}), c.Cleanup, func(sig os.Signal) {
+			received = sig
+		})
+
+		if received != syscall.SIGHUP {
+			t.Errorf("Expected the configured action to receive %v, but got %v", syscall.SIGHUP, received)
+		}
+		if !a.Changes["change"].Reverted {
+			t.Errorf("Expected changes to be reverted before the configured action ran")
+		}
+	})
+	t.Run("Evaluation completed", func(t *testing.T) {
+		c := &ControlEvaluation{}
+		done := make(chan struct{})
+		close(done)
+		called := false
+		handleInterrupt(make(chan os.Signal), done, c.Cleanup, func(os.Signal) {
+			called = true
+		})
+		if called {
+			t.Errorf("Expected no action to be taken once the evaluation completed")
+		}
+	})
+}
+
+func TestFilterByLabels(t *testing.T) {
+	platform := &ControlEvaluation{Control_Id: "platform"}
+	platform.SetLabel("team", "platform")
+	platform.SetLabel("framework", "cis")
+	security := &ControlEvaluation{Control_Id: "security"}
+	security.SetLabel("team", "security")
+	security.SetLabel("framework", "cis")
+	unlabeled := &ControlEvaluation{Control_Id: "unlabeled"}
+	evals := []*ControlEvaluation{platform, security, unlabeled}
+
+	tests := []struct {
+		testName string
+		selector map[string]string
+		expected []string
+	}{
+		{
+			testName: "Empty selector",
+			selector: map[string]string{},
+			expected: []string{"platform", "security", "unlabeled"},
+		},
+		{
+			testName: "Shared label",
+			selector: map[string]string{"framework": "cis"},
+			expected: []string{"platform", "security"},
+		},
+		{
+			testName: "Multiple labels",
+			selector: map[string]string{"framework": "cis", "team": "security"},
+			expected: []string{"security"},
+		},
+		{
+			testName: "No matches",
+			selector: map[string]string{"team": "networking"},
+			expected: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			matched := FilterByLabels(evals, test.selector)
+			if len(matched) != len(test.expected) {
+				t.Fatalf("Expected %d matches, but got %d", len(test.expected), len(matched))
+			}
+			for i, eval := range matched {
+				if eval.Control_Id != test.expected[i] {
+					t.Errorf("Expected match %d to be %s, but it was %s", i, test.expected[i], eval.Control_Id)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateOne(t *testing.T) {
+	newControl := func() *ControlEvaluation {
+		return &ControlEvaluation{
+			Assessments: []*Assessment{
+				{Requirement_Id: "first", Description: "first", Applicability: testingApplicability, Steps: []AssessmentStep{failingAssessmentStep}},
+				{Requirement_Id: "second", Description: "second", Applicability: testingApplicability, Steps: []AssessmentStep{passingAssessmentStep}},
+			},
+		}
+	}
+
+	t.Run("Found", func(t *testing.T) {
+		c := newControl()
+		assessment, err := c.EvaluateOne("second", nil, testingApplicability, false)
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if assessment != c.Assessments[1] {
+			t.Fatalf("Expected the matching assessment to be returned")
+		}
+		if assessment.Result != Passed {
+			t.Errorf("Expected the assessment Result to be %v, but it was %v", Passed, assessment.Result)
+		}
+		if c.Assessments[0].Steps_Executed != 0 {
+			t.Errorf("Expected other assessments not to run")
+		}
+		if c.Result != NotRun {
+			t.Errorf("Expected the control Result to be unchanged, but it was %v", c.Result)
+		}
+	})
+	t.Run("Not found", func(t *testing.T) {
+		c := newControl()
+		assessment, err := c.EvaluateOne("missing", nil, testingApplicability, false)
+		if err == nil {
+			t.Error("Expected an error, but got nil")
+		}
+		if assessment != nil {
+			t.Error("Expected no assessment to be returned")
+		}
+	})
+}
+
+func TestSortAssessments(t *testing.T) {
+	newControl := func() *ControlEvaluation {
+		return &ControlEvaluation{
+			Assessments: []*Assessment{
+				{Requirement_Id: "b", Applicability: []string{"tlp_red"}, Result: Passed},
+				{Requirement_Id: "c", Applicability: []string{"tlp_amber"}, Result: Failed},
+				{Requirement_Id: "a", Applicability: []string{"tlp_clear"}, Result: NeedsReview},
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		key      AssessmentSortKey
+		expected []string
+	}{
+		{name: "By ID", key: SortById, expected: []string{"a", "b", "c"}},
+		{name: "By severity", key: SortBySeverity, expected: []string{"c", "a", "b"}},
+		{name: "By applicability", key: SortByApplicability, expected: []string{"c", "a", "b"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newControl()
+			c.SortAssessments(test.key)
+			for i, id := range test.expected {
+				if c.Assessments[i].Requirement_Id != id {
+					t.Errorf("Expected assessment %d to be %s, but got %s", i, id, c.Assessments[i].Requirement_Id)
+				}
+			}
+		})
+	}
+	t.Run("By severity before evaluation", func(t *testing.T) {
+		c := newControl()
+		for _, assessment := range c.Assessments {
+			assessment.Result = NotRun
+		}
+		c.SortAssessments(SortBySeverity)
+		for i, id := range []string{"b", "c", "a"} {
+			if c.Assessments[i].Requirement_Id != id {
+				t.Errorf("Expected assessment %d to keep its insertion order as %s, but got %s", i, id, c.Assessments[i].Requirement_Id)
+			}
+		}
+	})
+}
+
+func TestEvaluateWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := &Assessment{Requirement_Id: "first", Description: "first", Applicability: testingApplicability}
+	first.NewChange("change", "target", "description", nil, goodApplyFunc, goodRevertFunc)
+	first.AddStep(func(payload interface{}, changes map[string]*Change) (Result, string) {
+		changes["change"].Apply()
+		cancel()
+		return Passed, "applied change"
+	})
+	second := &Assessment{Requirement_Id: "second", Description: "second", Applicability: testingApplicability, Steps: []AssessmentStep{passingAssessmentStep}}
+	c := &ControlEvaluation{Assessments: []*Assessment{first, second}}
+
+	err := c.EvaluateWithContext(ctx, nil, testingApplicability, true)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the error to be %v, but got %v", context.Canceled, err)
+	}
+	if c.Result != Unknown {
+		t.Errorf("Expected the control Result to be %v, but it was %v", Unknown, c.Result)
+	}
+	if !first.Changes["change"].Reverted {
+		t.Errorf("Expected the applied change to be reverted by cleanup")
+	}
+	if second.Steps_Executed != 0 || second.Result != NotRun {
+		t.Errorf("Expected the remaining assessment to be skipped, but it ran %d steps with Result %v", second.Steps_Executed, second.Result)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		name             string
+		steps            []AssessmentStep
+		expectedResult   Result
+		expectedComplete bool
+	}{
+		{
+			name:             "All assessments run",
+			steps:            []AssessmentStep{passingAssessmentStep, needsReviewAssessmentStep},
+			expectedResult:   NeedsReview,
+			expectedComplete: true,
+		},
+		{
+			name:             "Halted before the last assessment",
+			steps:            []AssessmentStep{failingAssessmentStep, passingAssessmentStep},
+			expectedResult:   Failed,
+			expectedComplete: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &ControlEvaluation{}
+			for i, step := range test.steps {
+				id := string(rune('a' + i))
+				c.AddAssessment(id, id, testingApplicability, []AssessmentStep{step})
+			}
+			c.Evaluate(nil, testingApplicability, false)
+			if c.Result != test.expectedResult {
+				t.Errorf("Expected Result to be %v, but it was %v", test.expectedResult, c.Result)
+			}
+			if c.Complete != test.expectedComplete {
+				t.Errorf("Expected Complete to be %v, but it was %v", test.expectedComplete, c.Complete)
+			}
+		})
+	}
+}
+
+func TestFilterByResult(t *testing.T) {
+	c := &ControlEvaluation{
+		Control_Id: "CTRL-01",
+		Result:     Failed,
+		Setup:      func() error { return nil },
+		Assessments: []*Assessment{
+			{Requirement_Id: "passed", Result: Passed},
+			{Requirement_Id: "failed", Result: Failed, Message: "failed", Retry_Messages: []string{"first try"}},
+			{Requirement_Id: "not-applicable", Result: NotApplicable},
+			{Requirement_Id: "needs-review", Result: NeedsReview},
+		},
+	}
+
+	filtered := c.FilterByResult(Failed, NeedsReview, Unknown)
+
+	if len(filtered.Assessments) != 2 || filtered.Assessments[0].Requirement_Id != "failed" || filtered.Assessments[1].Requirement_Id != "needs-review" {
+		t.Errorf("Expected only the failed and needs-review assessments, but got %d assessments", len(filtered.Assessments))
+	}
+	if filtered.Control_Id != c.Control_Id || filtered.Result != c.Result {
+		t.Errorf("Expected the control fields to be preserved")
+	}
+	if len(c.Assessments) != 4 {
+		t.Errorf("Expected the original control to keep all 4 assessments, but it has %d", len(c.Assessments))
+	}
+	if filtered.Setup != nil {
+		t.Errorf("Expected the execution hooks not to be carried over")
+	}
+	if filtered.Assessments[0] == c.Assessments[1] || filtered.Assessments[0].Message != "failed" {
+		t.Errorf("Expected a copy of the failed assessment with its results")
+	}
+	filtered.Assessments[0].Retry_Messages[0] = "changed"
+	if c.Assessments[1].Retry_Messages[0] != "first try" {
+		t.Errorf("Expected changes to the filtered assessment not to affect the original")
+	}
+}
+
+func TestMessageFormatter(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter MessageFormatter
+		expected  string
+	}{
+		{name: "Default", formatter: nil, expected: "please check"},
+		{name: "Summary", formatter: SummaryMessage, expected: "1 Passed, 1 Needs Review"},
+		{name: "Custom", formatter: func(assessments []*Assessment) string { return "custom" }, expected: "custom"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &ControlEvaluation{Message_Formatter: test.formatter}
+			c.AddAssessment("a", "a", testingApplicability, []AssessmentStep{passingAssessmentStep})
+			c.AddAssessment("b", "b", testingApplicability, []AssessmentStep{func(interface{}, map[string]*Change) (Result, string) {
+				return NeedsReview, "please check"
+			}})
+			c.Evaluate(nil, testingApplicability, false)
+			if c.Message != test.expected {
+				t.Errorf("Expected Message to be %q, but got %q", test.expected, c.Message)
+			}
+		})
+	}
+}
+
+func TestChangeConflicts(t *testing.T) {
+	newControl := func(exclusive bool) *ControlEvaluation {
+		c := &ControlEvaluation{Exclusive_Change_Targets: exclusive}
+		first := c.AddAssessment("first", "first", testingApplicability, []AssessmentStep{passingAssessmentStep})
+		first.NewChange("open-port", "firewall", "open a port", nil, goodApplyFunc, goodRevertFunc)
+		second := c.AddAssessment("second", "second", testingApplicability, []AssessmentStep{passingAssessmentStep})
+		second.NewChange("close-port", "firewall", "close a port", nil, goodApplyFunc, goodRevertFunc)
+		second.NewChange("rotate-key", "key", "rotate a key", nil, goodApplyFunc, goodRevertFunc)
+		second.NewReadOnlyChange("list-rules", "firewall", "list the firewall rules", nil)
+		return c
+	}
+
+	conflicts := newControl(false).ChangeConflicts()
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, but got %d", len(conflicts))
+	}
+	expected := "changes first/open-port and second/close-port both target firewall"
+	if conflicts[0].Error() != expected {
+		t.Errorf("Expected conflict %q, but got %q", expected, conflicts[0])
+	}
+	if err := newControl(false).Validate(); err != nil {
+		t.Errorf("Expected conflicts not to fail validation by default, but got %v", err)
+	}
+	if err := newControl(true).Validate(); err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected validation to report the conflict, but got %v", err)
+	}
+}
+
+func TestGetAssessment(t *testing.T) {
+	c := &ControlEvaluation{}
+	first := c.AddAssessment("duplicate", "first", testingApplicability, []AssessmentStep{passingAssessmentStep})
+	c.AddAssessment("duplicate", "second", testingApplicability, []AssessmentStep{passingAssessmentStep})
+	unique := c.AddAssessment("unique", "unique", testingApplicability, []AssessmentStep{passingAssessmentStep})
+
+	if assessment, ok := c.GetAssessment("unique"); !ok || assessment != unique {
+		t.Errorf("Expected to find the unique assessment")
+	}
+	if _, ok := c.GetAssessment("missing"); ok {
+		t.Errorf("Expected a missing requirement id not to be found")
+	}
+	if assessment, ok := c.GetAssessment("duplicate"); !ok || assessment != first {
+		t.Errorf("Expected the first assessment with a duplicate id to be returned")
+	}
+
+	appended := &Assessment{Requirement_Id: "appended"}
+	c.Assessments = append(c.Assessments, appended)
+	if assessment, ok := c.GetAssessment("appended"); !ok || assessment != appended {
+		t.Errorf("Expected to find an assessment appended directly to Assessments")
+	}
+}
+
+func TestEvaluateWarning(t *testing.T) {
+	warningStep := func(interface{}, map[string]*Change) (Result, string) {
+		return Warning, "deprecated setting in use"
+	}
+	c := &ControlEvaluation{
+		Name:       "warning-control",
+		Control_Id: "warning-control",
+		Assessments: []*Assessment{
+			{
+				Requirement_Id: "warning",
+				Description:    "warning assessment",
+				Applicability:  testingApplicability,
+				Steps:          []AssessmentStep{warningStep, passingAssessmentStep},
+			},
+			{
+				Requirement_Id: "dependent",
+				Description:    "depends on the warning assessment",
+				Applicability:  testingApplicability,
+				Depends_On:     []string{"warning"},
+				Steps:          []AssessmentStep{passingAssessmentStep},
+			},
+		},
+	}
+	c.Evaluate(nil, testingApplicability, false)
+
+	if c.Result != Warning || !c.Result.IsPass() {
+		t.Errorf("Expected a passing Warning result, but got %s", c.Result)
+	}
+	if steps := c.Assessments[0].Steps_Executed; steps != 2 {
+		t.Errorf("Expected a Warning not to halt the assessment, but %d steps ran", steps)
+	}
+	if c.Assessments[1].Result != Passed {
+		t.Errorf("Expected a Warning to satisfy dependencies, but got %s", c.Assessments[1].Result)
+	}
+}
+
+func TestEvaluateCleanupOnPanic(t *testing.T) {
+	a := &Assessment{
+		Requirement_Id: "panics",
+		Description:    "applies a change and then panics",
+		Applicability:  testingApplicability,
+	}
+	change := a.NewChange("change", "target", "description", nil, goodApplyFunc, goodRevertFunc)
+	a.Steps = []AssessmentStep{
+		func(interface{}, map[string]*Change) (Result, string) {
+			change.Apply()
+			panic("unexpected")
+		},
+	}
+	c := &ControlEvaluation{Name: "panics", Control_Id: "panics", Assessments: []*Assessment{a}}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatalf("Expected the panic to propagate from Evaluate")
+			}
+		}()
+		c.Evaluate(nil, testingApplicability, true)
+	}()
+
+	if !change.Applied || !change.Reverted {
+		t.Errorf("Expected the applied change to be reverted despite the panic, but got applied=%t, reverted=%t", change.Applied, change.Reverted)
+	}
+	if c.Corrupted_State {
+		t.Errorf("Expected Corrupted_State to be false after a successful cleanup")
+	}
+}
+
+func TestControlEvaluationString(t *testing.T) {
+	c := &ControlEvaluation{
+		Control_Id:  "CCC.C01",
+		Result:      Passed,
+		Assessments: []*Assessment{{Requirement_Id: "first"}, {Requirement_Id: "second"}, {Requirement_Id: "third"}},
+	}
+	if actual := fmt.Sprint(c); actual != "CCC.C01: Passed (3 assessments)" {
+		t.Errorf("Unexpected format %q", actual)
+	}
+}
+
+func TestSerializedFieldNames(t *testing.T) {
+	c := &ControlEvaluation{Name: "serialization", Control_Id: "CCC.C01", Remediation_Guide: "https://example.com/guide"}
+	a := c.AddAssessment("CCC.C01.TR01", "serialization", testingApplicability, []AssessmentStep{passingAssessmentStep})
+	a.NewChange("change", "bucket", "make the bucket private", nil, goodApplyFunc, goodRevertFunc)
+	c.Evaluate(nil, testingApplicability, true)
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{`"control-id":"CCC.C01"`, `"remediation-guide":"https://example.com/guide"`, `"requirement-id":"CCC.C01.TR01"`, `"steps-executed":1`, `"target-name":"bucket"`} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected the JSON to contain %s, but got %s", expected, data)
+		}
+	}
+
+	t.Run("Assessment JSON round trip", func(t *testing.T) {
+		original := a.Normalized()
+		data, err := json.Marshal(original)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var decoded Assessment
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !EqualResults(original, &decoded) {
+			t.Errorf("Expected the assessment to survive a round trip, but got %+v", decoded)
+		}
+	})
+	t.Run("ControlEvaluation JSON round trip", func(t *testing.T) {
+		evidenceStep := func(ctx context.Context, payload interface{}, changes map[string]*Change) StepResult {
+			changes["private"].Apply()
+			return StepResult{
+				Result:   NeedsReview,
+				Message:  "[manual] check the bucket",
+				Data:     map[string]interface{}{"buckets": []interface{}{"logs"}, "public": true},
+				Evidence: []Evidence{{Name: "policy", Content_Type: "application/json", Content: []byte(`{"public":true}`)}},
+			}
+		}
+
+		c := &ControlEvaluation{
+			Name:              "round trip",
+			Control_Id:        "CCC.C02",
+			Remediation_Guide: "https://example.com/guide",
+			Revert_Policy:     &RevertPolicy{Attempts: 2, Stop_On_Failure: true},
+		}
+		c.SetLabel("team", "platform")
+		populated := c.AddAssessment("CCC.C02.TR01", "populated", testingApplicability, []AssessmentStep{passingAssessmentStep})
+		populated.Context_Steps = []ContextStep{evidenceStep, passingContextStep}
+		populated.Retry_Policy = &RetryPolicy{Attempts: 2}
+		populated.Rerun_Policy = RerunSkip
+		populated.SetLabel("severity", "high")
+		populated.NewChange("private", "bucket", "make the bucket private", map[string]interface{}{"acl": "private"}, goodApplyFunc, badRevertFunc)
+		populated.Changes["private"].Rollback_Window = time.Hour
+		populated.Sub_Assessments = []*Assessment{{
+			Requirement_Id: "CCC.C02.TR01.1",
+			Description:    "child",
+			Applicability:  testingApplicability,
+			Context_Steps:  []ContextStep{erroringContextStep},
+		}}
+		c.AddAssessment("CCC.C02.TR02", "not applicable", []string{"other"}, []AssessmentStep{passingAssessmentStep})
+		c.Evaluate(nil, testingApplicability, true)
+		if !c.Corrupted_State || populated.Changes["private"].Applied_At.IsZero() || len(populated.Sub_Assessments[0].Step_Error_Messages) != 1 {
+			t.Fatalf("Expected a fully populated evaluation, but got %+v", c)
+		}
+
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(string(data), `"schema-version":"`+SchemaVersion+`"`) {
+			t.Errorf("Expected the JSON to record the schema version, but got %s", data)
+		}
+		var decoded ControlEvaluation
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		again, err := json.Marshal(&decoded)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(again) != string(data) {
+			t.Errorf("Expected the control to survive a round trip, but got\n%s\ninstead of\n%s", again, data)
+		}
+	})
+	t.Run("Unversioned JSON", func(t *testing.T) {
+		var decoded ControlEvaluation
+		err := json.Unmarshal([]byte(`{"Control_Id":"CCC.C01","Result":"Passed"}`), &decoded)
+		if !errors.Is(err, ErrSchemaVersion) {
+			t.Errorf("Expected %v, but got %v", ErrSchemaVersion, err)
+		}
+	})
+	t.Run("ControlEvaluation YAML", func(t *testing.T) {
+		data, err := yaml.Marshal(c)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(string(data), "schema-version: \""+SchemaVersion+"\"\n") || !strings.Contains(string(data), "control-id: CCC.C01") {
+			t.Errorf("Expected the YAML to record the schema version alongside the fields, but got:\n%s", data)
+		}
+	})
+	t.Run("Change YAML round trip", func(t *testing.T) {
+		original := &Change{Target_Name: "bucket", Description: "make the bucket private", Applied: true, Reverted: true}
+		data, err := yaml.Marshal(original)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(string(data), "target-name: bucket") {
+			t.Errorf("Expected the YAML to use the tagged names, but got:\n%s", data)
+		}
+		var decoded Change
+		if err := yaml.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if decoded.Target_Name != original.Target_Name || decoded.Description != original.Description || !decoded.Applied || !decoded.Reverted {
+			t.Errorf("Expected the change to survive a round trip, but got %+v", decoded)
+		}
+	})
+}
+
+func TestSetupAndTeardown(t *testing.T) {
+	newControl := func(events *[]string, setupErr error) *ControlEvaluation {
+		c := &ControlEvaluation{
+			Name:       "session",
+			Control_Id: "session",
+			Setup: func() error {
+				*events = append(*events, "setup")
+				return setupErr
+			},
+			Teardown: func() {
+				*events = append(*events, "teardown")
+			},
+			Before_Assessment: func(a *Assessment) {
+				*events = append(*events, "assessment "+a.Requirement_Id)
+			},
+		}
+		a := c.AddAssessment("first", "first", testingApplicability, []AssessmentStep{failingAssessmentStep})
+		a.NewChange("change", "target", "description", nil, goodApplyFunc, func() error {
+			*events = append(*events, "revert")
+			return nil
+		}).Apply()
+		c.AddAssessment("second", "second", testingApplicability, []AssessmentStep{passingAssessmentStep})
+		return c
+	}
+
+	t.Run("Order", func(t *testing.T) {
+		var events []string
+		c := newControl(&events, nil)
+		c.Evaluate(nil, testingApplicability, true)
+
+		expected := []string{"setup", "assessment first", "revert", "teardown"}
+		if strings.Join(events, ", ") != strings.Join(expected, ", ") {
+			t.Errorf("Expected %v, but got %v", expected, events)
+		}
+	})
+	t.Run("Setup error", func(t *testing.T) {
+		var events []string
+		c := newControl(&events, errors.New("could not connect"))
+		err := c.TryEvaluate(nil, testingApplicability, true)
+
+		if err == nil || c.Result != Unknown || c.Message != "setup failed: could not connect" {
+			t.Errorf("Expected the setup error to abort the evaluation as Unknown, but got %s: %v", c.Result, err)
+		}
+		expected := []string{"setup", "teardown"}
+		if strings.Join(events, ", ") != strings.Join(expected, ", ") {
+			t.Errorf("Expected %v, but got %v", expected, events)
+		}
+	})
+}
+
+func TestFailedAssessments(t *testing.T) {
+	withResults := func(results ...Result) *ControlEvaluation {
+		c := &ControlEvaluation{}
+		for i, result := range results {
+			c.Assessments = append(c.Assessments, &Assessment{Requirement_Id: fmt.Sprintf("%s-%d", result, i), Result: result})
+		}
+		return c
+	}
+	first := withResults(Passed, Failed, NeedsReview, Unknown, Failed)
+	second := withResults(NotApplicable, Failed, Warning)
+
+	failed := first.FailedAssessments()
+	if len(failed) != 2 || failed[0] != first.Assessments[1] || failed[1] != first.Assessments[4] {
+		t.Errorf("Expected only the 2 Failed assessments in order, but got %v", failed)
+	}
+	all := AllFailedAssessments([]*ControlEvaluation{first, second})
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 failed assessments across both controls, but got %v", all)
+	}
+	for _, assessment := range all {
+		if assessment.Result != Failed {
+			t.Errorf("Expected only Failed assessments, but got %s", assessment)
+		}
+	}
+	if failed := withResults(Passed, NeedsReview).FailedAssessments(); failed != nil {
+		t.Errorf("Expected no failed assessments, but got %v", failed)
+	}
+}
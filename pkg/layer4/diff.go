@@ -0,0 +1,62 @@
+package layer4
+
+// ResultTransition describes a requirement whose Result differs between two evaluation runs
+type ResultTransition struct {
+	Requirement_Id string // Requirement_Id is the unique identifier for the requirement that changed
+	Previous       Result // Previous is the Result from the earlier run
+	Current        Result // Current is the Result from the later run
+}
+
+// IsRegression returns true if the Current result is more severe than the Previous result
+func (t ResultTransition) IsRegression() bool {
+	return t.Current.SeverityRank() > t.Previous.SeverityRank()
+}
+
+// EvaluationDiff describes how the assessments of a control evaluation changed between two runs
+type EvaluationDiff struct {
+	Transitions []ResultTransition // Transitions lists each requirement whose Result changed, in the order of the current run
+	Added       []string           // Added lists the requirement IDs present only in the current run
+	Removed     []string           // Removed lists the requirement IDs present only in the previous run
+}
+
+// Diff compares two runs of the same control evaluation, matching assessments by Requirement_Id.
+// It is intended for drift detection, such as alerting when a requirement that previously passed now fails.
+func Diff(previous, current *ControlEvaluation) EvaluationDiff {
+	var diff EvaluationDiff
+	previousResults := make(map[string]Result)
+	for _, assessment := range previous.Assessments {
+		previousResults[assessment.Requirement_Id] = assessment.Result
+	}
+	currentIds := make(map[string]bool)
+	for _, assessment := range current.Assessments {
+		currentIds[assessment.Requirement_Id] = true
+		previousResult, ok := previousResults[assessment.Requirement_Id]
+		if !ok {
+			diff.Added = append(diff.Added, assessment.Requirement_Id)
+			continue
+		}
+		if previousResult != assessment.Result {
+			diff.Transitions = append(diff.Transitions, ResultTransition{
+				Requirement_Id: assessment.Requirement_Id,
+				Previous:       previousResult,
+				Current:        assessment.Result,
+			})
+		}
+	}
+	for _, assessment := range previous.Assessments {
+		if !currentIds[assessment.Requirement_Id] {
+			diff.Removed = append(diff.Removed, assessment.Requirement_Id)
+		}
+	}
+	return diff
+}
+
+// Regressions returns the transitions where the Result became more severe
+func (d EvaluationDiff) Regressions() (regressions []ResultTransition) {
+	for _, transition := range d.Transitions {
+		if transition.IsRegression() {
+			regressions = append(regressions, transition)
+		}
+	}
+	return
+}
@@ -0,0 +1,46 @@
+package layer4
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	previous := &ControlEvaluation{Assessments: []*Assessment{
+		{Requirement_Id: "regressed", Result: Passed},
+		{Requirement_Id: "improved", Result: Failed},
+		{Requirement_Id: "unchanged", Result: Passed},
+		{Requirement_Id: "removed", Result: Passed},
+	}}
+	current := &ControlEvaluation{Assessments: []*Assessment{
+		{Requirement_Id: "regressed", Result: Failed},
+		{Requirement_Id: "improved", Result: Passed},
+		{Requirement_Id: "unchanged", Result: Passed},
+		{Requirement_Id: "added", Result: NeedsReview},
+	}}
+
+	diff := Diff(previous, current)
+
+	expectedTransitions := []ResultTransition{
+		{Requirement_Id: "regressed", Previous: Passed, Current: Failed},
+		{Requirement_Id: "improved", Previous: Failed, Current: Passed},
+	}
+	if !reflect.DeepEqual(diff.Transitions, expectedTransitions) {
+		t.Errorf("Expected transitions %v, but got %v", expectedTransitions, diff.Transitions)
+	}
+	if !diff.Transitions[0].IsRegression() {
+		t.Errorf("Expected Passed to Failed to be a regression")
+	}
+	if diff.Transitions[1].IsRegression() {
+		t.Errorf("Expected Failed to Passed to be an improvement")
+	}
+	if regressions := diff.Regressions(); len(regressions) != 1 || regressions[0].Requirement_Id != "regressed" {
+		t.Errorf("Expected only the regressed requirement in Regressions, but got %v", regressions)
+	}
+	if !reflect.DeepEqual(diff.Added, []string{"added"}) {
+		t.Errorf("Expected added requirements [added], but got %v", diff.Added)
+	}
+	if !reflect.DeepEqual(diff.Removed, []string{"removed"}) {
+		t.Errorf("Expected removed requirements [removed], but got %v", diff.Removed)
+	}
+}
@@ -0,0 +1,14 @@
+// Package layer4 provides the types and helpers for Layer 4 of the SCI model, the evaluation of
+// code, configurations, and deployments against Layer 2 controls.
+//
+// A ControlEvaluation groups the Assessments for a single control. Each Assessment runs a series of
+// steps against the target data, and may make Changes to the target that are reverted once the
+// evaluation completes.
+//
+// When serialized to JSON or YAML, field names are written in lowercase kebab-case, such as
+// "requirement-id" for Requirement_Id, as described by schemas/layer-4.cue. This follows the
+// convention of the YAML tags on the Layer 2 types, which have no JSON tags of their own.
+// Earlier releases wrote the Go field names, such as "Requirement_Id", without a version, so each
+// serialized ControlEvaluation now records its SchemaVersion, and unmarshalling one written in any
+// other format returns ErrSchemaVersion rather than silently dropping its fields.
+package layer4
@@ -0,0 +1,28 @@
+package layer4
+
+// Evidence is an artifact supporting the result of an assessment, such as a configuration dump or API response.
+// Evidence is either provided inline as Content, or referenced by URI.
+type Evidence struct {
+	Name         string `json:"name" yaml:"name"`                 // Name is a human-readable name for the evidence
+	Content_Type string `json:"content-type" yaml:"content-type"` // Content_Type is the media type of the evidence, such as application/json or image/png
+	Content      []byte `json:"content" yaml:"content"`           // Content is the evidence itself, if it is provided inline
+	URI          string `json:"uri" yaml:"uri"`                   // URI is the location of the evidence, if it is not provided inline
+}
+
+// AddEvidence attaches inline evidence to the Assessment
+func (a *Assessment) AddEvidence(name, contentType string, content []byte) {
+	a.Evidence = append(a.Evidence, Evidence{
+		Name:         name,
+		Content_Type: contentType,
+		Content:      content,
+	})
+}
+
+// AddEvidenceURI attaches evidence to the Assessment by reference
+func (a *Assessment) AddEvidenceURI(name, contentType, uri string) {
+	a.Evidence = append(a.Evidence, Evidence{
+		Name:         name,
+		Content_Type: contentType,
+		URI:          uri,
+	})
+}
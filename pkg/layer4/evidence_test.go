@@ -0,0 +1,58 @@
+package layer4
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAddEvidence(t *testing.T) {
+	a := &Assessment{
+		Requirement_Id: "evidence",
+		Description:    "evidence assessment",
+		Applicability:  testingApplicability,
+		Context_Steps: []ContextStep{
+			func(ctx context.Context, payload interface{}, changes map[string]*Change) StepResult {
+				return StepResult{
+					Result:   Passed,
+					Evidence: []Evidence{{Name: "api response", Content_Type: "application/json", Content: []byte(`{"enabled":true}`)}},
+				}
+			},
+		},
+	}
+	a.AddEvidence("config dump", "text/plain", []byte("encryption: enabled"))
+	a.AddEvidenceURI("screenshot", "image/png", "https://example.com/screenshot.png")
+	a.Run(nil, false)
+
+	if len(a.Evidence) != 3 {
+		t.Fatalf("expected 3 evidence items, got %d", len(a.Evidence))
+	}
+	if a.Evidence[2].Name != "api response" {
+		t.Errorf("expected step evidence to be attached last, got %q", a.Evidence[2].Name)
+	}
+
+	serializedJSON, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("unexpected error serializing to JSON: %v", err)
+	}
+	var decoded struct{ Evidence []Evidence }
+	if err := json.Unmarshal(serializedJSON, &decoded); err != nil {
+		t.Fatalf("unexpected error decoding JSON: %v", err)
+	}
+	if len(decoded.Evidence) != 3 || string(decoded.Evidence[0].Content) != "encryption: enabled" || decoded.Evidence[1].URI != "https://example.com/screenshot.png" {
+		t.Errorf("expected evidence to survive JSON serialization, got %+v", decoded.Evidence)
+	}
+
+	serializedYAML, err := yaml.Marshal(a)
+	if err != nil {
+		t.Fatalf("unexpected error serializing to YAML: %v", err)
+	}
+	for _, expected := range []string{"config dump", "https://example.com/screenshot.png", "api response"} {
+		if !strings.Contains(string(serializedYAML), expected) {
+			t.Errorf("expected YAML to contain %q, got:\n%s", expected, serializedYAML)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package layer4
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvHeader is the column layout written by ExportCSV
+var csvHeader = []string{"Control_Id", "Requirement_Id", "Description", "Result", "Message", "Run_Duration"}
+
+// ExportCSV writes a flat table with a header row and one row per assessment across all evaluations
+func ExportCSV(w io.Writer, evals []*ControlEvaluation) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, eval := range evals {
+		for _, assessment := range eval.Assessments {
+			err := writer.Write([]string{
+				eval.Control_Id,
+				assessment.Requirement_Id,
+				assessment.Description,
+				assessment.Result.String(),
+				assessment.Message,
+				assessment.Run_Duration,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ndjsonRecord is the object written by ExportNDJSON for each assessment,
+// with the assessment's fields alongside the context of the evaluation it belongs to
+type ndjsonRecord struct {
+	Control_Id      string `json:"control-id"`
+	Control_Name    string `json:"control-name"`
+	Control_Result  Result `json:"control-result"`
+	Control_Message string `json:"control-message"`
+	*Assessment
+}
+
+// ExportNDJSON writes one standalone JSON object per line for each assessment across all evaluations,
+// so that large result sets can be streamed into line-oriented tools such as jq or a bulk ingest API
+func ExportNDJSON(w io.Writer, evals []*ControlEvaluation) error {
+	encoder := json.NewEncoder(w)
+	for _, eval := range evals {
+		for _, assessment := range eval.Assessments {
+			err := encoder.Encode(ndjsonRecord{
+				Control_Id:      eval.Control_Id,
+				Control_Name:    eval.Name,
+				Control_Result:  eval.Result,
+				Control_Message: eval.Message,
+				Assessment:      assessment,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to export %s for %s: %w", assessment.Requirement_Id, eval.Control_Id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// markdownSymbol is the emoji used to represent each result in Markdown reports
+var markdownSymbol = map[Result]string{
+	NotRun:        "⏸️",
+	Passed:        "✅",
+	Failed:        "❌",
+	NeedsReview:   "👀",
+	NotApplicable: "➖",
+	Unknown:       "❓",
+	Warning:       "⚠️",
+}
+
+// ExportMarkdown writes a human-readable report with a section for each evaluation,
+// containing a summary of result counts and a table of its assessments.
+// Any assessment-level Remediation is shown alongside the assessment's message,
+// and the Review_Reason is shown alongside NeedsReview results so that manual items stand out.
+func ExportMarkdown(w io.Writer, evals []*ControlEvaluation) error {
+	var b strings.Builder
+	for _, eval := range evals {
+		title := eval.Control_Id
+		if eval.Name != "" {
+			title = fmt.Sprintf("%s: %s", eval.Control_Id, eval.Name)
+		}
+		fmt.Fprintf(&b, "## %s\n\n", title)
+		fmt.Fprintf(&b, "**Result:** %s %s", markdownSymbol[eval.Result], eval.Result)
+		if summary := summarizeResults(eval.Assessments); summary != "" {
+			fmt.Fprintf(&b, " (%s)", summary)
+		}
+		b.WriteString("\n\n")
+		if eval.Remediation_Guide != "" {
+			fmt.Fprintf(&b, "[Remediation Guide](%s)\n\n", eval.Remediation_Guide)
+		}
+		if len(eval.Assessments) > 0 {
+			b.WriteString("| Requirement | Result | Message |\n")
+			b.WriteString("|-------------|--------|---------|\n")
+			for _, assessment := range eval.Assessments {
+				message := escapeMarkdownCell(assessment.Message)
+				if assessment.Remediation != "" {
+					message += "<br>**Remediation:** " + escapeMarkdownCell(assessment.Remediation)
+				}
+				result := fmt.Sprintf("%s %s", markdownSymbol[assessment.Result], assessment.Result)
+				if assessment.Result == NeedsReview && assessment.Review_Reason != "" {
+					result += fmt.Sprintf(" (%s)", assessment.Review_Reason)
+				}
+				fmt.Fprintf(&b, "| %s | %s | %s |\n",
+					escapeMarkdownCell(assessment.Requirement_Id),
+					result,
+					message,
+				)
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// summarizeResults describes the number of assessments with each result, such as "2 Passed, 1 Failed"
+func summarizeResults(assessments []*Assessment) string {
+	counts := make(map[Result]int)
+	for _, assessment := range assessments {
+		counts[assessment.Result]++
+	}
+	var parts []string
+	for result := Result(0); int(result) < len(toString); result++ {
+		if counts[result] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[result], result))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// escapeMarkdownCell prevents pipes and newlines from breaking a Markdown table row
+func escapeMarkdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
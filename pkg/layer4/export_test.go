@@ -0,0 +1,167 @@
+package layer4
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var exportTestData = []*ControlEvaluation{
+	{
+		Control_Id:        "CTRL-01",
+		Result:            Failed,
+		Remediation_Guide: "https://example.com/ctrl-01",
+		Assessments: []*Assessment{
+			{
+				Requirement_Id: "CTRL-01.1",
+				Description:    "first requirement",
+				Result:         Passed,
+				Message:        "all good",
+				Run_Duration:   "1ms",
+			},
+			{
+				Requirement_Id: "CTRL-01.2",
+				Description:    "second requirement",
+				Result:         Failed,
+				Message:        "found a problem, with a comma\nand a newline",
+				Run_Duration:   "2ms",
+			},
+		},
+	},
+	{
+		Control_Id: "CTRL-02",
+		Result:     NeedsReview,
+		Assessments: []*Assessment{
+			{
+				Requirement_Id: "CTRL-02.1",
+				Description:    "third requirement",
+				Result:         NeedsReview,
+				Message:        "please check",
+				Remediation:    "ask a maintainer",
+			},
+		},
+	},
+}
+
+func TestExportCSV(t *testing.T) {
+	t.Run("Evaluations", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := ExportCSV(&buf, exportTestData); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("expected valid CSV, got error: %v", err)
+		}
+		if len(records) != 4 {
+			t.Fatalf("expected a header and 3 rows, got %d records", len(records))
+		}
+		for i, column := range csvHeader {
+			if records[0][i] != column {
+				t.Errorf("expected column %d to be %s, got %s", i, column, records[0][i])
+			}
+		}
+		expected := []string{"CTRL-01", "CTRL-01.2", "second requirement", "Failed", "found a problem, with a comma\nand a newline", "2ms"}
+		for i, value := range expected {
+			if records[2][i] != value {
+				t.Errorf("expected column %d of row 2 to be %q, got %q", i, value, records[2][i])
+			}
+		}
+	})
+	t.Run("Empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := ExportCSV(&buf, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := "Control_Id,Requirement_Id,Description,Result,Message,Run_Duration\n"
+		if buf.String() != expected {
+			t.Errorf("expected only the header row, got %q", buf.String())
+		}
+	})
+}
+
+func TestExportNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportNDJSON(&buf, exportTestData); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scanner := bufio.NewScanner(&buf)
+	var lines []map[string]interface{}
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("expected line %d to be valid JSON, got error: %v", len(lines)+1, err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	expected := map[string]interface{}{
+		"control-id":     "CTRL-01",
+		"control-result": "Failed",
+		"requirement-id": "CTRL-01.2",
+		"result":         "Failed",
+		"message":        "found a problem, with a comma\nand a newline",
+	}
+	for key, value := range expected {
+		if lines[1][key] != value {
+			t.Errorf("expected %s of line 2 to be %q, got %q", key, value, lines[1][key])
+		}
+	}
+	if lines[2]["control-id"] != "CTRL-02" {
+		t.Errorf("expected line 3 to belong to CTRL-02, got %v", lines[2]["control-id"])
+	}
+
+	buf.Reset()
+	if err := ExportNDJSON(&buf, nil); err != nil || buf.Len() != 0 {
+		t.Errorf("expected no output for no evaluations, got %q (%v)", buf.String(), err)
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportMarkdown(&buf, exportTestData); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report := buf.String()
+
+	expected := []string{
+		"## CTRL-01",
+		"**Result:** ❌ Failed (1 Passed, 1 Failed)",
+		"[Remediation Guide](https://example.com/ctrl-01)",
+		"| Requirement | Result | Message |",
+		"| CTRL-01.2 | ❌ Failed | found a problem, with a comma<br>and a newline |",
+		"## CTRL-02",
+		"| CTRL-02.1 | 👀 Needs Review | please check<br>**Remediation:** ask a maintainer |",
+	}
+	for _, line := range expected {
+		if !strings.Contains(report, line) {
+			t.Errorf("expected report to contain %q, got:\n%s", line, report)
+		}
+	}
+	if strings.Count(report, "| Requirement | Result | Message |") != 2 {
+		t.Errorf("expected a table for each control, got:\n%s", report)
+	}
+
+	t.Run("Manual review", func(t *testing.T) {
+		var buf bytes.Buffer
+		evals := []*ControlEvaluation{{
+			Control_Id: "CTRL-03",
+			Result:     NeedsReview,
+			Assessments: []*Assessment{
+				{Requirement_Id: "CTRL-03.1", Result: NeedsReview, Message: "confirm the plan was reviewed", Review_Reason: ManualVerification},
+			},
+		}}
+		if err := ExportMarkdown(&buf, evals); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := "| CTRL-03.1 | 👀 Needs Review (Manual Verification) | confirm the plan was reviewed |"
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected report to contain %q, got:\n%s", expected, buf.String())
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package layer4
+
+// Finding is a tool-agnostic summary of an assessment result, used as the common input for exporters
+type Finding struct {
+	Id          string     // Id is the requirement ID of the assessment that produced the finding
+	Control_Id  string     // Control_Id is the unique identifier for the control the assessment belongs to
+	Title       string     // Title is the human-readable description of the assessment
+	Description string     // Description is the human-readable result of the assessment
+	Result      Result     // Result is the result of the assessment
+	Remediation string     // Remediation describes how to resolve the finding
+	Evidence    []Evidence // Evidence is the artifacts supporting the result of the assessment
+}
+
+// ToFinding maps the Assessment's fields into a Finding for the control with the provided ID.
+// Remediation is taken from the Assessment; use ControlEvaluation.Findings to fall back to the control's Remediation_Guide.
+func (a *Assessment) ToFinding(controlId string) Finding {
+	return Finding{
+		Id:          a.Requirement_Id,
+		Control_Id:  controlId,
+		Title:       a.Description,
+		Description: a.Message,
+		Result:      a.Result,
+		Remediation: a.Remediation,
+		Evidence:    a.Evidence,
+	}
+}
+
+// Findings returns a Finding for each assessment in the control evaluation,
+// using the control's Remediation_Guide for any assessment without its own Remediation
+func (c *ControlEvaluation) Findings() []Finding {
+	findings := make([]Finding, 0, len(c.Assessments))
+	for _, assessment := range c.Assessments {
+		finding := assessment.ToFinding(c.Control_Id)
+		if finding.Remediation == "" {
+			finding.Remediation = c.Remediation_Guide
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
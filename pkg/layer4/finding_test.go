@@ -0,0 +1,58 @@
+package layer4
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindings(t *testing.T) {
+	a := &Assessment{
+		Requirement_Id: "OSPS-AC-01.01",
+		Description:    "MFA is required for all maintainers",
+		Result:         Failed,
+		Message:        "2 maintainers do not have MFA enabled",
+	}
+	a.AddEvidenceURI("members", "application/json", "https://example.com/members.json")
+	c := &ControlEvaluation{
+		Control_Id:        "OSPS-AC-01",
+		Remediation_Guide: "https://example.com/remediation/mfa",
+		Assessments:       []*Assessment{a},
+	}
+
+	expected := Finding{
+		Id:          "OSPS-AC-01.01",
+		Control_Id:  "OSPS-AC-01",
+		Title:       "MFA is required for all maintainers",
+		Description: "2 maintainers do not have MFA enabled",
+		Result:      Failed,
+		Evidence:    a.Evidence,
+	}
+	if finding := a.ToFinding(c.Control_Id); !reflect.DeepEqual(finding, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, finding)
+	}
+
+	expected.Remediation = "https://example.com/remediation/mfa"
+	findings := c.Findings()
+	if len(findings) != 1 || !reflect.DeepEqual(findings[0], expected) {
+		t.Errorf("Expected [%+v], but got %+v", expected, findings)
+	}
+}
+
+func TestAssessmentRemediation(t *testing.T) {
+	specific := &Assessment{Requirement_Id: "specific"}
+	specific.SetRemediation("enable branch protection on the default branch")
+	general := &Assessment{Requirement_Id: "general"}
+	c := &ControlEvaluation{
+		Remediation_Guide: "https://example.com/remediation",
+		Assessments:       []*Assessment{specific, general},
+	}
+
+	findings := c.Findings()
+
+	if findings[0].Remediation != "enable branch protection on the default branch" {
+		t.Errorf("Expected the assessment Remediation to take precedence, but got %q", findings[0].Remediation)
+	}
+	if findings[1].Remediation != "https://example.com/remediation" {
+		t.Errorf("Expected the control Remediation_Guide as a fallback, but got %q", findings[1].Remediation)
+	}
+}
@@ -0,0 +1,38 @@
+package layer4
+
+import "strings"
+
+// ResultFlag implements flag.Value so that a Result can be accepted on the command line,
+// such as with flag.Var(&minResult, "min-result", "the minimum acceptable result").
+// Values are parsed with ParseResult, and the spaces in multi-word results may be omitted,
+// so both "Needs Review" and "NeedsReview" are accepted.
+type ResultFlag Result
+
+// String returns the string representation of the flag's Result
+func (f *ResultFlag) String() string {
+	if f == nil {
+		return NotRun.String()
+	}
+	return Result(*f).String()
+}
+
+// Set parses the provided value as a Result and stores it in the flag
+func (f *ResultFlag) Set(value string) error {
+	result, err := ParseResult(value)
+	if err != nil {
+		for candidate, str := range toString {
+			if strings.ReplaceAll(str, " ", "") == value {
+				*f = ResultFlag(candidate)
+				return nil
+			}
+		}
+		return err
+	}
+	*f = ResultFlag(result)
+	return nil
+}
+
+// Result returns the Result held by the flag
+func (f ResultFlag) Result() Result {
+	return Result(f)
+}
@@ -0,0 +1,48 @@
+package layer4
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestResultFlag(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  Result
+		expectErr bool
+	}{
+		{value: "Passed", expected: Passed},
+		{value: "Needs Review", expected: NeedsReview},
+		{value: "NeedsReview", expected: NeedsReview},
+		{value: "NotApplicable", expected: NotApplicable},
+		{value: "passed", expectErr: true},
+		{value: "Bogus", expectErr: true},
+		{value: "", expectErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			var minResult ResultFlag
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			fs.Var(&minResult, "min-result", "the minimum acceptable result")
+
+			err := fs.Parse([]string{"-min-result=" + test.value})
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error parsing %q, but got %s", test.value, minResult.Result())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if minResult.Result() != test.expected {
+				t.Errorf("Expected %s, but got %s", test.expected, minResult.Result())
+			}
+			if minResult.String() != test.expected.String() {
+				t.Errorf("Expected String to return %q, but got %q", test.expected.String(), minResult.String())
+			}
+		})
+	}
+}
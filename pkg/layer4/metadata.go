@@ -0,0 +1,42 @@
+package layer4
+
+import "context"
+
+// metadataKey is the context key under which the EvaluationMetadata is stored
+type metadataKey struct{}
+
+// EvaluationMetadata is ambient information about the running evaluation, made available to ContextSteps
+// through MetadataFromContext so that they can log and correlate without it being added to the target data.
+type EvaluationMetadata struct {
+	Control_Id     string // Control_Id is the ID of the control being evaluated, set by the ControlEvaluation
+	Requirement_Id string // Requirement_Id is the ID of the running assessment, set by the Assessment
+	Correlation_Id string // Correlation_Id is an optional caller-provided ID for correlating logs across systems
+	Target_Name    string // Target_Name is an optional caller-provided name of the target being evaluated
+}
+
+// WithMetadata returns a context carrying the provided metadata. Callers may use it to set the
+// Correlation_Id and Target_Name before calling EvaluateWithContext or RunWithContext;
+// the Control_Id and Requirement_Id are filled in as the evaluation runs.
+func WithMetadata(ctx context.Context, metadata EvaluationMetadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// MetadataFromContext returns the EvaluationMetadata carried by the context, or false if there is none
+func MetadataFromContext(ctx context.Context) (EvaluationMetadata, bool) {
+	metadata, ok := ctx.Value(metadataKey{}).(EvaluationMetadata)
+	return metadata, ok
+}
+
+// withMetadata returns a context whose metadata names the ControlEvaluation
+func (c *ControlEvaluation) withMetadata(ctx context.Context) context.Context {
+	metadata, _ := MetadataFromContext(ctx)
+	metadata.Control_Id = c.Control_Id
+	return WithMetadata(ctx, metadata)
+}
+
+// withMetadata returns a context whose metadata names the Assessment
+func (a *Assessment) withMetadata(ctx context.Context) context.Context {
+	metadata, _ := MetadataFromContext(ctx)
+	metadata.Requirement_Id = a.Requirement_Id
+	return WithMetadata(ctx, metadata)
+}
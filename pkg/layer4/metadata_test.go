@@ -0,0 +1,65 @@
+package layer4
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMetadataFromContext(t *testing.T) {
+	var seen []EvaluationMetadata
+	recordingStep := func(ctx context.Context, payload interface{}, changes map[string]*Change) StepResult {
+		metadata, ok := MetadataFromContext(ctx)
+		if !ok {
+			return StepResult{Result: Failed, Message: "no metadata"}
+		}
+		seen = append(seen, metadata)
+		return StepResult{Result: Passed}
+	}
+
+	t.Run("Control evaluation", func(t *testing.T) {
+		seen = nil
+		c := &ControlEvaluation{
+			Name:       "metadata",
+			Control_Id: "CCC.C01",
+			Assessments: []*Assessment{
+				{Requirement_Id: "CCC.C01.TR01", Description: "first", Applicability: testingApplicability, Context_Steps: []ContextStep{recordingStep}},
+				{Requirement_Id: "CCC.C01.TR02", Description: "second", Applicability: testingApplicability, Context_Steps: []ContextStep{recordingStep}},
+			},
+		}
+		ctx := WithMetadata(context.Background(), EvaluationMetadata{Correlation_Id: "run-42", Target_Name: "bucket"})
+
+		if err := c.EvaluateWithContext(ctx, nil, testingApplicability, false); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []EvaluationMetadata{
+			{Control_Id: "CCC.C01", Requirement_Id: "CCC.C01.TR01", Correlation_Id: "run-42", Target_Name: "bucket"},
+			{Control_Id: "CCC.C01", Requirement_Id: "CCC.C01.TR02", Correlation_Id: "run-42", Target_Name: "bucket"},
+		}
+		if len(seen) != len(expected) {
+			t.Fatalf("Expected %d steps to see metadata, but got %d", len(expected), len(seen))
+		}
+		for i := range expected {
+			if seen[i] != expected[i] {
+				t.Errorf("Expected %+v, but got %+v", expected[i], seen[i])
+			}
+		}
+	})
+	t.Run("Standalone assessment", func(t *testing.T) {
+		seen = nil
+		a := &Assessment{
+			Requirement_Id: "standalone",
+			Description:    "standalone",
+			Applicability:  testingApplicability,
+			Context_Steps:  []ContextStep{recordingStep},
+		}
+		a.RunWithContext(context.Background(), nil, false)
+		if len(seen) != 1 || seen[0] != (EvaluationMetadata{Requirement_Id: "standalone"}) {
+			t.Errorf("Expected only the Requirement_Id to be set, but got %+v", seen)
+		}
+	})
+	t.Run("No metadata", func(t *testing.T) {
+		if _, ok := MetadataFromContext(context.Background()); ok {
+			t.Errorf("Expected no metadata on a plain context")
+		}
+	})
+}
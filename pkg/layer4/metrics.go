@@ -0,0 +1,14 @@
+package layer4
+
+import "time"
+
+// MetricsSink receives counters and observations as a ControlEvaluation runs, allowing the evaluation
+// to be monitored by a metrics system such as Prometheus without this package depending on it.
+// Implementations must be safe for concurrent use if the same sink is shared between evaluations.
+type MetricsSink interface {
+	IncResult(result Result)                // IncResult is called with the Result of each assessment that is run
+	ObserveDuration(duration time.Duration) // ObserveDuration is called with the time taken to run each assessment
+	IncChangesApplied()                     // IncChangesApplied is called for each change that was applied by an assessment that is run
+	IncChangesReverted()                    // IncChangesReverted is called for each applied change that is reverted during cleanup
+	IncRevertFailures()                     // IncRevertFailures is called for each applied change that fails to revert during cleanup
+}
@@ -0,0 +1,68 @@
+package layer4
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordingSink is a MetricsSink that records every call it receives
+type recordingSink struct {
+	results   map[Result]int
+	durations []time.Duration
+	applied   int
+	reverted  int
+	failures  int
+}
+
+func (r *recordingSink) IncResult(result Result) {
+	if r.results == nil {
+		r.results = make(map[Result]int)
+	}
+	r.results[result]++
+}
+
+func (r *recordingSink) ObserveDuration(duration time.Duration) {
+	r.durations = append(r.durations, duration)
+}
+
+func (r *recordingSink) IncChangesApplied()  { r.applied++ }
+func (r *recordingSink) IncChangesReverted() { r.reverted++ }
+func (r *recordingSink) IncRevertFailures()  { r.failures++ }
+
+func TestMetricsSink(t *testing.T) {
+	applyingStep := func(payload interface{}, changes map[string]*Change) (Result, string) {
+		for _, change := range changes {
+			change.Apply()
+		}
+		return Passed, "applied changes"
+	}
+	first := &Assessment{Requirement_Id: "first", Description: "first", Applicability: testingApplicability, Steps: []AssessmentStep{applyingStep}}
+	first.NewChange("good", "target", "description", nil, goodApplyFunc, goodRevertFunc)
+	second := &Assessment{Requirement_Id: "second", Description: "second", Applicability: testingApplicability, Steps: []AssessmentStep{applyingStep, needsReviewAssessmentStep}}
+	second.NewChange("bad", "target", "description", nil, goodApplyFunc, func() error { return errors.New("revert failed") })
+	sink := &recordingSink{}
+	c := &ControlEvaluation{Assessments: []*Assessment{first, second}, Metrics: sink}
+
+	c.Evaluate(nil, testingApplicability, true)
+
+	if sink.results[Passed] != 1 || sink.results[NeedsReview] != 1 {
+		t.Errorf("Expected one Passed and one NeedsReview result, but got %v", sink.results)
+	}
+	if len(sink.durations) != 2 {
+		t.Errorf("Expected 2 durations to be observed, but got %d", len(sink.durations))
+	}
+	if sink.applied != 2 || sink.reverted != 1 || sink.failures != 1 {
+		t.Errorf("Expected 2 applied, 1 reverted, and 1 failed change, but got %d, %d, and %d", sink.applied, sink.reverted, sink.failures)
+	}
+
+	t.Run("Nil sink", func(t *testing.T) {
+		c := &ControlEvaluation{Assessments: []*Assessment{
+			{Requirement_Id: "nil", Description: "nil", Applicability: testingApplicability, Steps: []AssessmentStep{passingAssessmentStep}},
+		}}
+		c.Evaluate(nil, testingApplicability, false)
+		if c.Result != Passed {
+			t.Errorf("Expected the evaluation to run without a sink, but got %v", c.Result)
+		}
+	})
+}
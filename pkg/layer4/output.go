@@ -0,0 +1,38 @@
+package layer4
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// outputKey is the context key under which an assessment's output writer is stored
+type outputKey struct{}
+
+// outputWriter appends everything written to it to the Output of an Assessment
+type outputWriter struct {
+	mu         sync.Mutex
+	assessment *Assessment
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.assessment.Output += string(p)
+	return len(p), nil
+}
+
+// withOutput returns a context carrying a writer that captures output into the Assessment
+func (a *Assessment) withOutput(ctx context.Context) context.Context {
+	return context.WithValue(ctx, outputKey{}, &outputWriter{assessment: a})
+}
+
+// StepOutput returns a writer for a ContextStep to record raw output, such as the stdout and stderr
+// of an external tool, which is captured into the running Assessment's Output.
+// If the context does not belong to a running assessment, the output is discarded.
+func StepOutput(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(outputKey{}).(*outputWriter); ok {
+		return w
+	}
+	return io.Discard
+}
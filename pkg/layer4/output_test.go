@@ -0,0 +1,36 @@
+package layer4
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestStepOutput(t *testing.T) {
+	toolStep := func(ctx context.Context, payload interface{}, _ map[string]*Change) StepResult {
+		fmt.Fprintf(StepOutput(ctx), "scanned %v\n", payload)
+		return StepResult{Result: Passed}
+	}
+	a := &Assessment{
+		Requirement_Id: "output",
+		Description:    "output",
+		Applicability:  testingApplicability,
+		Context_Steps:  []ContextStep{toolStep, toolStep},
+	}
+
+	a.Run("bucket", false)
+
+	expected := "scanned bucket\nscanned bucket\n"
+	if a.Output != expected {
+		t.Errorf("Expected Output to be %q, but got %q", expected, a.Output)
+	}
+	if clone := a.Clone(); clone.Output != "" {
+		t.Errorf("Expected a clone to have no Output, but got %q", clone.Output)
+	}
+
+	t.Run("Outside an assessment", func(t *testing.T) {
+		if _, err := fmt.Fprint(StepOutput(context.Background()), "discarded"); err != nil {
+			t.Errorf("Expected output outside an assessment to be discarded without error, but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package layer4
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Profile groups the control evaluations that make up a framework, such as NIST 800-53 or a CIS benchmark
+type Profile struct {
+	Name     string               `json:"name" yaml:"name"`         // Name is the name of the framework, such as "CIS Kubernetes Benchmark"
+	Version  string               `json:"version" yaml:"version"`   // Version is the version of the framework that the controls implement
+	Result   Result               `json:"result" yaml:"result"`     // Result is the aggregate result of the controls after the profile is evaluated
+	Controls []*ControlEvaluation `json:"controls" yaml:"controls"` // Controls are the control evaluations that belong to the framework
+}
+
+// ProfileSummary describes the outcome of a Profile evaluation at the framework level
+type ProfileSummary struct {
+	Name     string          // Name is the name of the framework
+	Version  string          // Version is the version of the framework
+	Result   Result          // Result is the aggregate result of the controls
+	Controls int             // Controls is the number of controls in the profile
+	Results  map[string]int  // Results is the number of controls with each result, keyed by the result's string representation
+	Score    ComplianceScore // Score is the compliance score across the assessments of every control
+}
+
+// NewProfile creates a Profile with the provided name, version, and controls
+func NewProfile(name string, version string, controls ...*ControlEvaluation) *Profile {
+	return &Profile{Name: name, Version: version, Controls: controls}
+}
+
+// AddControl adds a control evaluation to the profile
+func (p *Profile) AddControl(control *ControlEvaluation) {
+	p.Controls = append(p.Controls, control)
+}
+
+// Evaluate evaluates every control in the profile as described by ControlEvaluation.Evaluate,
+// setting the profile's Result to the aggregate result across all of them.
+func (p *Profile) Evaluate(targetData interface{}, userApplicability []string, changesAllowed bool) {
+	p.Result = EvaluateAll(p.Controls, targetData, userApplicability, changesAllowed, false)
+}
+
+// EvaluateWithContext behaves like Evaluate, passing ctx down to each control's EvaluateWithContext,
+// so that a deadline on ctx applies to the whole framework run. Once ctx is done, the control in progress
+// stops between steps and reverts its applied changes, and every control that has not started yet
+// is marked Unknown without running any of its assessments.
+// The returned error joins the error of each control that could not be evaluated cleanly, including ctx's error.
+func (p *Profile) EvaluateWithContext(ctx context.Context, targetData interface{}, userApplicability []string, changesAllowed bool) error {
+	p.Result = NotRun
+	var errs []error
+	for i, control := range p.Controls {
+		if err := ctx.Err(); err != nil {
+			for _, skipped := range p.Controls[i:] {
+				skipped.Complete = false
+				skipped.Result = Unknown
+				skipped.Message = fmt.Sprintf("evaluation cancelled: %v", err)
+				p.Result = UpdateAggregateResult(p.Result, skipped.Result)
+			}
+			errs = append(errs, err)
+			break
+		}
+		if err := control.EvaluateWithContext(ctx, targetData, userApplicability, changesAllowed); err != nil {
+			errs = append(errs, fmt.Errorf("control %s: %w", control.Control_Id, err))
+		}
+		p.Result = UpdateAggregateResult(p.Result, control.Result)
+	}
+	return errors.Join(errs...)
+}
+
+// Summary summarizes the profile's most recent evaluation
+func (p *Profile) Summary() ProfileSummary {
+	summary := ProfileSummary{
+		Name:     p.Name,
+		Version:  p.Version,
+		Result:   p.Result,
+		Controls: len(p.Controls),
+		Results:  make(map[string]int),
+		Score:    Score(p.Controls),
+	}
+	for _, control := range p.Controls {
+		summary.Results[control.Result.String()]++
+	}
+	return summary
+}
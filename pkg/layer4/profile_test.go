@@ -0,0 +1,97 @@
+package layer4
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfile(t *testing.T) {
+	newControl := func(id string, steps ...AssessmentStep) *ControlEvaluation {
+		c := &ControlEvaluation{Name: id, Control_Id: id}
+		c.AddAssessment(id, "assessment for "+id, testingApplicability, steps)
+		return c
+	}
+	profile := NewProfile("Example Benchmark", "1.0.0",
+		newControl("control-1", passingAssessmentStep),
+		newControl("control-2", needsReviewAssessmentStep),
+	)
+	profile.AddControl(newControl("control-3", passingAssessmentStep))
+
+	profile.Evaluate(nil, testingApplicability, false)
+
+	if profile.Result != NeedsReview {
+		t.Errorf("Expected the profile result to be NeedsReview, but got %s", profile.Result)
+	}
+	summary := profile.Summary()
+	if summary.Controls != 3 || summary.Results["Passed"] != 2 || summary.Results["Needs Review"] != 1 {
+		t.Errorf("Expected 3 controls with 2 Passed and 1 Needs Review, but got %+v", summary)
+	}
+	if summary.Score.Passed != 2 || summary.Score.Applicable != 3 {
+		t.Errorf("Expected a score of 2 of 3 applicable assessments, but got %+v", summary.Score)
+	}
+
+	data, err := json.Marshal(profile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{`"name":"Example Benchmark"`, `"version":"1.0.0"`, `"result":"Needs Review"`, `"control-id":"control-3"`} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected the serialized profile to contain %s, but got %s", expected, data)
+		}
+	}
+}
+
+func TestProfileEvaluateWithContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	slow := &ControlEvaluation{Name: "slow", Control_Id: "slow"}
+	a := &Assessment{Requirement_Id: "slow", Description: "applies a change and outlasts the deadline", Applicability: testingApplicability}
+	change := a.NewChange("change", "target", "description", nil, goodApplyFunc, goodRevertFunc)
+	a.AddContextStep(func(ctx context.Context, payload interface{}, changes map[string]*Change) StepResult {
+		changes["change"].Apply()
+		<-ctx.Done()
+		return StepResult{Result: Passed}
+	})
+	a.AddContextStep(passingContextStep)
+	slow.Assessments = append(slow.Assessments, a)
+	slow.AddAssessment("after-deadline", "runs after the deadline", testingApplicability, []AssessmentStep{passingAssessmentStep})
+
+	fast := &ControlEvaluation{Name: "fast", Control_Id: "fast"}
+	fast.AddAssessment("fast", "fast", testingApplicability, []AssessmentStep{passingAssessmentStep})
+	skipped := &ControlEvaluation{Name: "skipped", Control_Id: "skipped"}
+	skipped.AddAssessment("skipped", "skipped", testingApplicability, []AssessmentStep{passingAssessmentStep})
+
+	before := &ControlEvaluation{Name: "before", Control_Id: "before"}
+	before.AddAssessment("before", "before", testingApplicability, []AssessmentStep{passingAssessmentStep})
+	profile := NewProfile("Example Benchmark", "1.0.0", before, slow, fast, skipped)
+
+	err := profile.EvaluateWithContext(ctx, nil, testingApplicability, true)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to be reported, but got %v", err)
+	}
+	if before.Result != Passed {
+		t.Errorf("Expected the control run before the deadline to be Passed, but got %s", before.Result)
+	}
+	if slow.Result != Unknown || slow.Assessments[0].Steps_Executed != 1 || slow.Assessments[1].Result != NotRun {
+		t.Errorf("Expected the slow control to stop at the deadline, but got %s after %d steps", slow.Result, slow.Assessments[0].Steps_Executed)
+	}
+	if !change.Applied || !change.Reverted {
+		t.Errorf("Expected the applied change to be reverted after the deadline")
+	}
+	for _, control := range []*ControlEvaluation{fast, skipped} {
+		if control.Result != Unknown || control.Complete || control.Assessments[0].Steps_Executed != 0 {
+			t.Errorf("Expected %s to be Unknown without running, but got %s after %d steps", control.Control_Id, control.Result, control.Assessments[0].Steps_Executed)
+		}
+		if !strings.Contains(control.Message, "cancelled") {
+			t.Errorf("Expected %s to explain that it was cancelled, but got %q", control.Control_Id, control.Message)
+		}
+	}
+	if profile.Result != Unknown {
+		t.Errorf("Expected the profile result to be Unknown, but got %s", profile.Result)
+	}
+}
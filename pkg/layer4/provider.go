@@ -0,0 +1,77 @@
+package layer4
+
+import (
+	"context"
+)
+
+// AssessmentProvider supplies assessments one at a time, allowing them to be generated on demand
+// rather than materialized up front
+type AssessmentProvider interface {
+	// Next returns the next assessment, or false once there are no more assessments
+	Next() (*Assessment, bool)
+}
+
+// SliceProvider is an AssessmentProvider that yields the assessments in a slice, in order
+type SliceProvider struct {
+	assessments []*Assessment
+	index       int
+}
+
+// NewSliceProvider creates an AssessmentProvider for a slice of assessments
+func NewSliceProvider(assessments []*Assessment) *SliceProvider {
+	return &SliceProvider{assessments: assessments}
+}
+
+// Next returns the next assessment in the slice
+func (p *SliceProvider) Next() (*Assessment, bool) {
+	if p.index >= len(p.assessments) {
+		return nil, false
+	}
+	assessment := p.assessments[p.index]
+	p.index++
+	return assessment, true
+}
+
+// chainedProvider yields the assessments of each of its providers in turn
+type chainedProvider struct {
+	providers []AssessmentProvider
+}
+
+// chainProviders creates an AssessmentProvider that exhausts each provider before moving on to the next
+func chainProviders(providers ...AssessmentProvider) *chainedProvider {
+	return &chainedProvider{providers: providers}
+}
+
+// Next returns the next assessment from the first provider that is not yet exhausted
+func (p *chainedProvider) Next() (*Assessment, bool) {
+	for len(p.providers) > 0 {
+		if assessment, ok := p.providers[0].Next(); ok {
+			return assessment, true
+		}
+		p.providers = p.providers[1:]
+	}
+	return nil, false
+}
+
+// EvaluateProvider behaves like Evaluate, but pulls each assessment from the provider only when it is ready to run.
+// Assessments is replaced by the assessments pulled during this evaluation, which are appended as they are pulled
+// so that their results are reported, and the previous Result and Message are cleared.
+// Because assessments are not known in advance, they run in the order provided and the provider must yield
+// any assessment named in Depends_On before the assessments that depend on it. Each assessment is validated when
+// it runs, and one that repeats an earlier Requirement_Id stops the evaluation as Unknown.
+// Pulling stops once the evaluation halts; assessments not yet pulled are left with the provider.
+func (c *ControlEvaluation) EvaluateProvider(provider AssessmentProvider, targetData interface{}, userApplicability []string, changesAllowed bool) error {
+	return c.EvaluateProviderWithContext(context.Background(), provider, targetData, userApplicability, changesAllowed)
+}
+
+// EvaluateProviderWithContext behaves like EvaluateProvider, but stops pulling assessments once ctx is cancelled,
+// as described by EvaluateWithContext
+func (c *ControlEvaluation) EvaluateProviderWithContext(ctx context.Context, provider AssessmentProvider, targetData interface{}, userApplicability []string, changesAllowed bool) error {
+	c.Assessments = nil
+	c.Result = NotRun
+	c.Message = ""
+	c.indexMu.Lock()
+	c.index = nil
+	c.indexMu.Unlock()
+	return c.evaluate(ctx, targetData, userApplicability, changesAllowed, evaluateOptions{provider: provider})
+}
@@ -0,0 +1,130 @@
+package layer4
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// countingProvider wraps a SliceProvider, counting how many assessments have been pulled
+type countingProvider struct {
+	*SliceProvider
+	pulled int
+}
+
+func (p *countingProvider) Next() (*Assessment, bool) {
+	assessment, ok := p.SliceProvider.Next()
+	if ok {
+		p.pulled++
+	}
+	return assessment, ok
+}
+
+func TestEvaluateProvider(t *testing.T) {
+	newAssessment := func(id string, step AssessmentStep) *Assessment {
+		return &Assessment{Requirement_Id: id, Description: id, Applicability: testingApplicability, Steps: []AssessmentStep{step}}
+	}
+	tests := []struct {
+		name             string
+		assessments      []*Assessment
+		expectedResult   Result
+		expectedPulled   int
+		expectedComplete bool
+	}{
+		{
+			name:             "All passing",
+			assessments:      []*Assessment{newAssessment("a", passingAssessmentStep), newAssessment("b", passingAssessmentStep)},
+			expectedResult:   Passed,
+			expectedPulled:   2,
+			expectedComplete: true,
+		},
+		{
+			name:             "Stops pulling after a failure",
+			assessments:      []*Assessment{newAssessment("a", failingAssessmentStep), newAssessment("b", passingAssessmentStep)},
+			expectedResult:   Failed,
+			expectedPulled:   1,
+			expectedComplete: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider := &countingProvider{SliceProvider: NewSliceProvider(test.assessments)}
+			c := &ControlEvaluation{}
+			if err := c.EvaluateProvider(provider, nil, testingApplicability, false); err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+			if c.Result != test.expectedResult {
+				t.Errorf("Expected Result to be %v, but it was %v", test.expectedResult, c.Result)
+			}
+			if provider.pulled != test.expectedPulled || len(c.Assessments) != test.expectedPulled {
+				t.Errorf("Expected %d assessments to be pulled and recorded, but got %d and %d", test.expectedPulled, provider.pulled, len(c.Assessments))
+			}
+			if c.Complete != test.expectedComplete {
+				t.Errorf("Expected Complete to be %v, but it was %v", test.expectedComplete, c.Complete)
+			}
+		})
+	}
+
+	t.Run("Shares the evaluation lifecycle", func(t *testing.T) {
+		var events []string
+		c := &ControlEvaluation{
+			Halt_On_Unknown: true,
+			Setup: func() error {
+				events = append(events, "setup")
+				return nil
+			},
+			Teardown: func() {
+				events = append(events, "teardown")
+			},
+		}
+		assessments := func() []*Assessment {
+			return []*Assessment{newAssessment("a", unknownAssessmentStep), newAssessment("b", passingAssessmentStep)}
+		}
+		for i := 0; i < 2; i++ {
+			provider := &countingProvider{SliceProvider: NewSliceProvider(assessments())}
+			if err := c.EvaluateProvider(provider, nil, testingApplicability, false); err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+			if provider.pulled != 1 || c.Result != Unknown {
+				t.Errorf("Expected Halt_On_Unknown to stop pulling with %v, but got %v after %d assessments", Unknown, c.Result, provider.pulled)
+			}
+		}
+		if len(c.Assessments) != 1 {
+			t.Errorf("Expected Assessments to be replaced on each evaluation, but got %d assessments", len(c.Assessments))
+		}
+		if len(events) != 4 || events[0] != "setup" || events[1] != "teardown" {
+			t.Errorf("Expected Setup and Teardown around each evaluation, but got %v", events)
+		}
+		if c.Applicability_Summary.Assessments != 1 || c.Applicability_Summary.Matched != 1 {
+			t.Errorf("Expected the applicability summary to count the pulled assessments, but got %+v", c.Applicability_Summary)
+		}
+	})
+
+	t.Run("Duplicate requirement id", func(t *testing.T) {
+		c := &ControlEvaluation{}
+		provider := NewSliceProvider([]*Assessment{newAssessment("a", passingAssessmentStep), newAssessment("a", passingAssessmentStep)})
+		if err := c.EvaluateProvider(provider, nil, testingApplicability, false); err == nil || c.Result != Unknown {
+			t.Errorf("Expected a duplicate requirement id to stop the evaluation as %v, but got %v and %v", Unknown, c.Result, err)
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		provider := &countingProvider{SliceProvider: NewSliceProvider([]*Assessment{newAssessment("a", passingAssessmentStep)})}
+		c := &ControlEvaluation{}
+		if err := c.EvaluateProviderWithContext(ctx, provider, nil, testingApplicability, false); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected %v, but got %v", context.Canceled, err)
+		}
+		if provider.pulled != 0 {
+			t.Errorf("Expected no assessments to be pulled after cancellation, but got %d", provider.pulled)
+		}
+	})
+
+	t.Run("Empty provider", func(t *testing.T) {
+		c := &ControlEvaluation{}
+		if err := c.EvaluateProvider(NewSliceProvider(nil), nil, testingApplicability, false); !errors.Is(err, ErrNoAssessments) {
+			t.Errorf("Expected %v, but got %v", ErrNoAssessments, err)
+		}
+	})
+}
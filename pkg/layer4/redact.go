@@ -0,0 +1,98 @@
+package layer4
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RedactedValue is the mask that replaces values removed by a FieldRedactor
+const RedactedValue = "[REDACTED]"
+
+// Redactor returns a copy of the provided value with any sensitive data removed or masked.
+// It must not modify the provided value, which may be shared with the target data.
+type Redactor func(value interface{}) interface{}
+
+// FieldRedactor returns a Redactor that masks the fields with any of the provided names, at any depth.
+// The value is first converted to its JSON representation, so struct fields are matched by their JSON names,
+// and names are compared case-insensitively. A value that cannot be represented as JSON is masked entirely,
+// so that a secret is never emitted just because it could not be inspected.
+func FieldRedactor(fields ...string) Redactor {
+	redacted := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		redacted[strings.ToLower(field)] = true
+	}
+	return func(value interface{}) interface{} {
+		if value == nil {
+			return nil
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return RedactedValue
+		}
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return RedactedValue
+		}
+		return redactFields(generic, redacted)
+	}
+}
+
+// redactFields masks the entries of any maps within the value whose keys are in the redacted set
+func redactFields(value interface{}, redacted map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if redacted[strings.ToLower(key)] {
+				v[key] = RedactedValue
+			} else {
+				v[key] = redactFields(child, redacted)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactFields(child, redacted)
+		}
+	}
+	return value
+}
+
+// Redact applies the redactor to the Assessment's Value and inline Evidence, and to those of its Sub_Assessments,
+// so that secrets are removed before the Assessment is serialized into a report.
+// JSON evidence is decoded before it is passed to the redactor, and any other evidence is passed as a string.
+func (a *Assessment) Redact(redactor Redactor) {
+	a.Value = redactor(a.Value)
+	for i := range a.Evidence {
+		a.Evidence[i].Content = redactEvidence(a.Evidence[i], redactor)
+	}
+	for _, child := range a.Sub_Assessments {
+		child.Redact(redactor)
+	}
+}
+
+// Redact applies the redactor to each of the ControlEvaluation's assessments, as described by Assessment.Redact
+func (c *ControlEvaluation) Redact(redactor Redactor) {
+	for _, assessment := range c.Assessments {
+		assessment.Redact(redactor)
+	}
+}
+
+// redactEvidence returns the evidence content after it has been passed through the redactor
+func redactEvidence(evidence Evidence, redactor Redactor) []byte {
+	if evidence.Content == nil {
+		return nil
+	}
+	var value interface{} = string(evidence.Content)
+	decoded := false
+	if strings.Contains(evidence.Content_Type, "json") {
+		decoded = json.Unmarshal(evidence.Content, &value) == nil
+	}
+	redactedValue := redactor(value)
+	if text, ok := redactedValue.(string); ok && !decoded {
+		return []byte(text)
+	}
+	data, err := json.Marshal(redactedValue)
+	if err != nil {
+		return []byte(RedactedValue)
+	}
+	return data
+}
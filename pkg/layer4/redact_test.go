@@ -0,0 +1,87 @@
+package layer4
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type redactTestConfig struct {
+	Endpoint string `json:"endpoint"`
+	Token    string `json:"token"`
+	Nested   struct {
+		Password string `json:"password"`
+	} `json:"nested"`
+}
+
+func TestFieldRedactor(t *testing.T) {
+	config := redactTestConfig{Endpoint: "https://example.com", Token: "secret-token"}
+	config.Nested.Password = "secret-password"
+	redactor := FieldRedactor("TOKEN", "password")
+
+	data, err := json.Marshal(redactor(config))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := string(data)
+	if strings.Contains(output, "secret") {
+		t.Errorf("expected the secrets to be removed, got %s", output)
+	}
+	if strings.Count(output, RedactedValue) != 2 || !strings.Contains(output, "https://example.com") {
+		t.Errorf("expected only the designated fields to be masked, got %s", output)
+	}
+	if config.Token != "secret-token" {
+		t.Errorf("expected the original value not to be modified")
+	}
+
+	list := redactor([]interface{}{map[string]interface{}{"token": "secret-token"}})
+	if data, _ := json.Marshal(list); strings.Contains(string(data), "secret") {
+		t.Errorf("expected secrets within slices to be removed, got %s", data)
+	}
+	if redactor(func() {}) != RedactedValue {
+		t.Errorf("expected a value that cannot be inspected to be masked entirely")
+	}
+	if redactor(nil) != nil {
+		t.Errorf("expected nil to be left alone")
+	}
+}
+
+func TestAssessmentRedact(t *testing.T) {
+	a := &Assessment{
+		Requirement_Id: "redact",
+		Value:          map[string]string{"token": "secret-token", "region": "us-east-1"},
+		Evidence: []Evidence{
+			{Name: "config", Content_Type: "application/json", Content: []byte(`{"token":"secret-token"}`)},
+			{Name: "log", Content_Type: "text/plain", Content: []byte("token=secret-token")},
+			{Name: "screenshot", URI: "https://example.com/screenshot.png"},
+		},
+		Sub_Assessments: []*Assessment{{Requirement_Id: "child", Value: map[string]string{"token": "secret-token"}}},
+	}
+	c := &ControlEvaluation{Control_Id: "redact", Assessments: []*Assessment{a}}
+	c.Redact(func(value interface{}) interface{} {
+		if text, ok := value.(string); ok {
+			return strings.ReplaceAll(text, "secret-token", RedactedValue)
+		}
+		return FieldRedactor("token")(value)
+	})
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "secret") || strings.Contains(string(a.Evidence[0].Content), "secret") || strings.Contains(string(a.Evidence[1].Content), "secret") {
+		t.Errorf("expected the secrets to be removed, got %s", data)
+	}
+	if string(a.Evidence[1].Content) != "token="+RedactedValue {
+		t.Errorf("expected the text evidence to be masked, got %q", a.Evidence[1].Content)
+	}
+	if string(a.Evidence[0].Content) != `{"token":"`+RedactedValue+`"}` {
+		t.Errorf("expected the JSON evidence to be masked, got %s", a.Evidence[0].Content)
+	}
+	if a.Evidence[2].Content != nil {
+		t.Errorf("expected evidence without inline content to be left alone")
+	}
+	if !strings.Contains(string(data), "us-east-1") {
+		t.Errorf("expected the other fields to be kept, got %s", data)
+	}
+}
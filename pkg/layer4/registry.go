@@ -0,0 +1,43 @@
+package layer4
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	stepRegistryMu sync.RWMutex
+	stepRegistry   = make(map[string]string)
+)
+
+// RegisterStep records a human-readable description for an AssessmentStep or ContextStep,
+// keyed by the function name that the step is serialized as in reports.
+func RegisterStep(step fmt.Stringer, description string) {
+	stepRegistryMu.Lock()
+	defer stepRegistryMu.Unlock()
+	stepRegistry[step.String()] = description
+}
+
+// Describe returns the description registered for the step with the provided function name,
+// such as the name shown for a step in a serialized report
+func Describe(name string) (description string, ok bool) {
+	stepRegistryMu.RLock()
+	defer stepRegistryMu.RUnlock()
+	description, ok = stepRegistry[name]
+	return
+}
+
+// RegisteredSteps returns the sorted function names of every registered step,
+// so that tooling can list the available steps and check that configuration only refers to known ones.
+// The description of each step is available from Describe.
+func RegisteredSteps() []string {
+	stepRegistryMu.RLock()
+	defer stepRegistryMu.RUnlock()
+	names := make([]string, 0, len(stepRegistry))
+	for name := range stepRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,59 @@
+package layer4
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	RegisterStep(AssessmentStep(passingAssessmentStep), "always passes")
+	RegisterStep(ContextStep(passingContextStep), "always passes with a context")
+
+	tests := []struct {
+		name       string
+		stepName   string
+		expected   string
+		expectedOk bool
+	}{
+		{name: "Assessment step", stepName: AssessmentStep(passingAssessmentStep).String(), expected: "always passes", expectedOk: true},
+		{name: "Context step", stepName: ContextStep(passingContextStep).String(), expected: "always passes with a context", expectedOk: true},
+		{name: "Unregistered step", stepName: AssessmentStep(failingAssessmentStep).String(), expectedOk: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			description, ok := Describe(test.stepName)
+			if ok != test.expectedOk || description != test.expected {
+				t.Errorf("Expected (%q, %v), but got (%q, %v)", test.expected, test.expectedOk, description, ok)
+			}
+		})
+	}
+}
+
+func TestRegisteredSteps(t *testing.T) {
+	RegisterStep(AssessmentStep(unknownAssessmentStep), "always returns unknown")
+	RegisterStep(AssessmentStep(needsReviewAssessmentStep), "always needs review")
+	RegisterStep(AssessmentStep(passingAssessmentStep), "always passes")
+
+	names := RegisteredSteps()
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("Expected the step names to be sorted, but got %v", names)
+	}
+	listed := make(map[string]bool)
+	for _, name := range names {
+		if listed[name] {
+			t.Errorf("Expected %s to be listed once", name)
+		}
+		listed[name] = true
+		if _, ok := Describe(name); !ok {
+			t.Errorf("Expected %s to have a description", name)
+		}
+	}
+	for _, step := range []AssessmentStep{unknownAssessmentStep, needsReviewAssessmentStep, passingAssessmentStep} {
+		if !listed[step.String()] {
+			t.Errorf("Expected %s to be listed in %v", step, names)
+		}
+	}
+	if listed[AssessmentStep(failingAssessmentStep).String()] {
+		t.Errorf("Expected an unregistered step not to be listed")
+	}
+}
@@ -1,9 +1,14 @@
 package layer4
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
 
 // Result is an enum representing the result of a control evaluation
 // This is designed to restrict the possible result values to a set of known states
+// NotRun is deliberately the zero value, so that an unset Result is never mistaken for a passing state
 type Result int
 
 const (
@@ -13,6 +18,7 @@ const (
 	NeedsReview
 	NotApplicable
 	Unknown
+	Warning // Warning is an advisory result that is visible in reports but still counts as passing
 )
 
 var toString = map[Result]string{
@@ -22,12 +28,117 @@ var toString = map[Result]string{
 	NeedsReview:   "Needs Review",
 	NotApplicable: "Not Applicable",
 	Unknown:       "Unknown",
+	Warning:       "Warning",
+}
+
+// severityRank defines a canonical total ordering of results, where higher values are more severe
+var severityRank = map[Result]int{
+	NotRun:        0,
+	NotApplicable: 1,
+	Passed:        2,
+	Warning:       3,
+	NeedsReview:   4,
+	Unknown:       5,
+	Failed:        6,
 }
 
 func (r Result) String() string {
 	return toString[r]
 }
 
+// SeverityRank returns the severity of the result, where higher values are more severe.
+// This is intended for sorting and display, and is independent of UpdateAggregateResult's precedence.
+func (r Result) SeverityRank() int {
+	return severityRank[r]
+}
+
+// Less reports whether r should be sorted before other, placing more severe results first
+func (r Result) Less(other Result) bool {
+	return r.SeverityRank() > other.SeverityRank()
+}
+
+// IsPass returns true if the result is a passing state, including an advisory Warning
+func (r Result) IsPass() bool {
+	return r == Passed || r == Warning
+}
+
+// IsFail returns true if the result is a failing state
+func (r Result) IsFail() bool {
+	return r == Failed
+}
+
+// IsInconclusive returns true if the result neither passed nor failed, and requires further attention
+func (r Result) IsInconclusive() bool {
+	return r == NeedsReview || r == Unknown
+}
+
+// IsApplicable returns false only if the result indicates that the evaluation did not apply
+func (r Result) IsApplicable() bool {
+	return r != NotApplicable
+}
+
+// toSymbol is the unicode symbol used to represent each result in terminal output
+var toSymbol = map[Result]string{
+	NotRun:        "·",
+	Passed:        "✔",
+	Failed:        "✘",
+	NeedsReview:   "?",
+	NotApplicable: "-",
+	Unknown:       "!",
+	Warning:       "⚠",
+}
+
+// toColor is the ANSI escape code used to color each result in terminal output
+var toColor = map[Result]string{
+	NotRun:        "\033[90m", // gray
+	Passed:        "\033[32m", // green
+	Failed:        "\033[31m", // red
+	NeedsReview:   "\033[33m", // yellow
+	NotApplicable: "\033[90m", // gray
+	Unknown:       "\033[35m", // magenta
+	Warning:       "\033[36m", // cyan
+}
+
+const ansiReset = "\033[0m"
+
+// Symbol returns a single unicode symbol representing the result, for consistent terminal output
+func (r Result) Symbol() string {
+	return toSymbol[r]
+}
+
+// Render returns the result's symbol and name, such as "✔ Passed", wrapped in an ANSI color if color is true
+func (r Result) Render(color bool) string {
+	rendered := r.Symbol() + " " + r.String()
+	if !color {
+		return rendered
+	}
+	return toColor[r] + rendered + ansiReset
+}
+
+// ParseResult returns the Result with the provided string representation, such as "Needs Review"
+func ParseResult(s string) (Result, error) {
+	for result, str := range toString {
+		if str == s {
+			return result, nil
+		}
+	}
+	return NotRun, fmt.Errorf("unknown result: %q", s)
+}
+
+// UnmarshalJSON parses a Result from its string representation in JSON
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	result, err := ParseResult(s)
+	if err != nil {
+		return err
+	}
+	*r = result
+	return nil
+}
+
 // MarshalYAML ensures that Result is serialized as a string in YAML
 func (r Result) MarshalYAML() (interface{}, error) {
 	return r.String(), nil
@@ -39,6 +150,10 @@ func (r Result) MarshalJSON() ([]byte, error) {
 }
 
 // UpdateAggregateResult compares the current result with the new result and returns the most severe of the two.
+// A NotRun result never changes the aggregate, so folding a partially evaluated set of results only reflects
+// the results that exist so far; use ControlEvaluation.Complete to tell whether every assessment was accounted for.
+// A NotApplicable result only replaces NotRun and otherwise leaves the aggregate unchanged; earlier releases folded it
+// in as Passed, so an aggregate of only NotApplicable results is now NotApplicable rather than Passed.
 func UpdateAggregateResult(previous Result, new Result) Result {
 	if new == NotRun {
 		// Not Run should not overwrite anything
@@ -47,6 +162,14 @@ func UpdateAggregateResult(previous Result, new Result) Result {
 		return previous
 	}
 
+	if new == NotApplicable {
+		// NotApplicable should only overwrite NotRun, so that inapplicable results do not mask applicable ones
+		if previous == NotRun {
+			return NotApplicable
+		}
+		return previous
+	}
+
 	if previous == Failed || new == Failed {
 		// Failed should not be overwritten by anything
 		// Failed should overwrite anything
@@ -63,5 +186,84 @@ func UpdateAggregateResult(previous Result, new Result) Result {
 		// NeedsReview should overwrite Passed
 		return NeedsReview
 	}
+
+	if previous == Warning || new == Warning {
+		// Warning should be overwritten by anything except Passed
+		// Warning should overwrite Passed, while still counting as passing
+		return Warning
+	}
 	return Passed
 }
+
+// AggregateOption adjusts how AggregateWith folds a pair of results
+type AggregateOption func(*aggregateOptions)
+
+// aggregateOptions holds the adjustments made by AggregateOptions
+type aggregateOptions struct {
+	overrides map[[2]Result]Result // overrides maps a pair of results to the result that wins between them
+	ignored   map[Result]bool      // ignored results never change the aggregate
+}
+
+// OverrideResult makes winner take precedence over loser, in either order, when they are folded together.
+// For example, OverrideResult(NotApplicable, NeedsReview) lets a rollup treat an inapplicable result
+// as settling a question that would otherwise need review.
+func OverrideResult(winner, loser Result) AggregateOption {
+	return func(o *aggregateOptions) {
+		if o.overrides == nil {
+			o.overrides = make(map[[2]Result]Result)
+		}
+		o.overrides[[2]Result{winner, loser}] = winner
+		o.overrides[[2]Result{loser, winner}] = winner
+	}
+}
+
+// IgnoreResult prevents the provided result from changing the aggregate, in the same way as NotRun
+func IgnoreResult(ignored Result) AggregateOption {
+	return func(o *aggregateOptions) {
+		if o.ignored == nil {
+			o.ignored = make(map[Result]bool)
+		}
+		o.ignored[ignored] = true
+	}
+}
+
+// AggregateWith folds the new result into the previous result like UpdateAggregateResult, adjusted by the provided options.
+// The options only apply to this call, so the default ordering used everywhere else is unchanged.
+// Options compare one pair of results at a time; when folding a longer sequence, each step is decided independently.
+// The available options are:
+//   - OverrideResult, which makes one result take precedence over another
+//   - IgnoreResult, which prevents a result from changing the aggregate
+func AggregateWith(previous Result, new Result, opts ...AggregateOption) Result {
+	var o aggregateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.ignored[new] {
+		return previous
+	}
+	if winner, ok := o.overrides[[2]Result{previous, new}]; ok {
+		return winner
+	}
+	return UpdateAggregateResult(previous, new)
+}
+
+// AggregateResultAccumulator folds results using UpdateAggregateResult,
+// and is safe for concurrent use by multiple goroutines
+type AggregateResultAccumulator struct {
+	mu     sync.Mutex
+	result Result
+}
+
+// Add folds the new result into the aggregate
+func (a *AggregateResultAccumulator) Add(new Result) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.result = UpdateAggregateResult(a.result, new)
+}
+
+// Result returns the current aggregate result
+func (a *AggregateResultAccumulator) Result() Result {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.result
+}
@@ -1,6 +1,9 @@
 package layer4
 
 import (
+	"encoding/json"
+	"sort"
+	"sync"
 	"testing"
 )
 
@@ -34,6 +37,10 @@ func TestResultString(t *testing.T) {
 			result:   Unknown,
 			expected: "Unknown",
 		},
+		{
+			result:   Warning,
+			expected: "Warning",
+		},
 	}
 
 	for _, test := range tests {
@@ -45,3 +52,222 @@ func TestResultString(t *testing.T) {
 		})
 	}
 }
+
+func TestResultZeroValue(t *testing.T) {
+	var result Result
+	if result != NotRun {
+		t.Errorf("expected the zero value to be %s, got %s", NotRun, result)
+	}
+	if result.IsPass() {
+		t.Errorf("expected the zero value not to be a passing state")
+	}
+	var assessment Assessment
+	if assessment.Result.IsPass() {
+		t.Errorf("expected an unset Assessment Result not to be a passing state")
+	}
+}
+
+func TestUpdateAggregateResultNotApplicable(t *testing.T) {
+	tests := []struct {
+		previous Result
+		expected Result
+	}{
+		{previous: NotRun, expected: NotApplicable},
+		{previous: NotApplicable, expected: NotApplicable},
+		{previous: Passed, expected: Passed},
+		{previous: NeedsReview, expected: NeedsReview},
+		{previous: Unknown, expected: Unknown},
+		{previous: Failed, expected: Failed},
+	}
+	for _, test := range tests {
+		t.Run(test.previous.String(), func(t *testing.T) {
+			actual := UpdateAggregateResult(test.previous, NotApplicable)
+			if actual != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestUpdateAggregateResultWarning(t *testing.T) {
+	tests := []struct {
+		previous Result
+		expected Result
+	}{
+		{previous: NotRun, expected: Warning},
+		{previous: NotApplicable, expected: Warning},
+		{previous: Passed, expected: Warning},
+		{previous: Warning, expected: Warning},
+		{previous: NeedsReview, expected: NeedsReview},
+		{previous: Unknown, expected: Unknown},
+		{previous: Failed, expected: Failed},
+	}
+	for _, test := range tests {
+		t.Run(test.previous.String(), func(t *testing.T) {
+			actual := UpdateAggregateResult(test.previous, Warning)
+			if actual != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, actual)
+			}
+		})
+	}
+	if actual := UpdateAggregateResult(Warning, Passed); actual != Warning {
+		t.Errorf("expected a later Passed result not to hide a Warning, got %s", actual)
+	}
+}
+
+func TestAggregateWith(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous Result
+		new      Result
+		opts     []AggregateOption
+		expected Result
+	}{
+		{name: "No options", previous: NeedsReview, new: NotApplicable, expected: NeedsReview},
+		{name: "Override", previous: NeedsReview, new: NotApplicable, opts: []AggregateOption{OverrideResult(NotApplicable, NeedsReview)}, expected: NotApplicable},
+		{name: "Override in reverse order", previous: NotApplicable, new: NeedsReview, opts: []AggregateOption{OverrideResult(NotApplicable, NeedsReview)}, expected: NotApplicable},
+		{name: "Override of an unrelated pair", previous: Passed, new: Failed, opts: []AggregateOption{OverrideResult(NotApplicable, NeedsReview)}, expected: Failed},
+		{name: "Ignore", previous: Passed, new: Unknown, opts: []AggregateOption{IgnoreResult(Unknown)}, expected: Passed},
+		{name: "Ignore a previous result", previous: Unknown, new: Passed, opts: []AggregateOption{IgnoreResult(Unknown)}, expected: Unknown},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := AggregateWith(test.previous, test.new, test.opts...)
+			if actual != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, actual)
+			}
+		})
+	}
+
+	var folded Result
+	for _, result := range []Result{Passed, NeedsReview, NotApplicable} {
+		folded = AggregateWith(folded, result, OverrideResult(NotApplicable, NeedsReview))
+	}
+	if folded != NotApplicable {
+		t.Errorf("expected the override to change the fold to %s, got %s", NotApplicable, folded)
+	}
+	if actual := UpdateAggregateResult(NeedsReview, NotApplicable); actual != NeedsReview {
+		t.Errorf("expected the default fold to be unchanged, got %s", actual)
+	}
+}
+
+func TestAggregateResultAccumulator(t *testing.T) {
+	results := []Result{Passed, NeedsReview, Passed, NotRun, Unknown, Passed, Failed, Passed}
+
+	var expected Result
+	for _, result := range results {
+		expected = UpdateAggregateResult(expected, result)
+	}
+
+	accumulator := &AggregateResultAccumulator{}
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		for _, result := range results {
+			wg.Add(1)
+			go func(r Result) {
+				defer wg.Done()
+				accumulator.Add(r)
+			}(result)
+		}
+	}
+	wg.Wait()
+
+	if accumulator.Result() != expected {
+		t.Errorf("expected %s, got %s", expected, accumulator.Result())
+	}
+}
+
+func TestResultLess(t *testing.T) {
+	results := []Result{Passed, NotRun, Warning, NeedsReview, Failed, NotApplicable, Unknown}
+	expected := []Result{Failed, Unknown, NeedsReview, Warning, Passed, NotApplicable, NotRun}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Less(results[j])
+	})
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("expected %s at position %d, got %s", expected[i], i, results[i])
+		}
+	}
+}
+
+func TestResultPredicates(t *testing.T) {
+	tests := []struct {
+		result         Result
+		isPass         bool
+		isFail         bool
+		isInconclusive bool
+		isApplicable   bool
+	}{
+		{result: NotRun, isApplicable: true},
+		{result: Passed, isPass: true, isApplicable: true},
+		{result: Failed, isFail: true, isApplicable: true},
+		{result: NeedsReview, isInconclusive: true, isApplicable: true},
+		{result: NotApplicable},
+		{result: Unknown, isInconclusive: true, isApplicable: true},
+		{result: Warning, isPass: true, isApplicable: true},
+	}
+	for _, test := range tests {
+		t.Run(test.result.String(), func(t *testing.T) {
+			if test.result.IsPass() != test.isPass {
+				t.Errorf("expected IsPass to be %t", test.isPass)
+			}
+			if test.result.IsFail() != test.isFail {
+				t.Errorf("expected IsFail to be %t", test.isFail)
+			}
+			if test.result.IsInconclusive() != test.isInconclusive {
+				t.Errorf("expected IsInconclusive to be %t", test.isInconclusive)
+			}
+			if test.result.IsApplicable() != test.isApplicable {
+				t.Errorf("expected IsApplicable to be %t", test.isApplicable)
+			}
+		})
+	}
+}
+
+func TestResultJSONRoundTrip(t *testing.T) {
+	for result := Result(0); int(result) < len(toString); result++ {
+		data, err := json.Marshal(result)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var parsed Result
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if parsed != result {
+			t.Errorf("expected %s, got %s", result, parsed)
+		}
+	}
+	if _, err := ParseResult("Mostly Passed"); err == nil {
+		t.Errorf("expected an error for an unknown result")
+	}
+}
+
+func TestResultSymbol(t *testing.T) {
+	tests := []struct {
+		result   Result
+		expected string
+	}{
+		{result: NotRun, expected: "·"},
+		{result: Passed, expected: "✔"},
+		{result: Failed, expected: "✘"},
+		{result: NeedsReview, expected: "?"},
+		{result: NotApplicable, expected: "-"},
+		{result: Unknown, expected: "!"},
+		{result: Warning, expected: "⚠"},
+	}
+	for _, test := range tests {
+		t.Run(test.result.String(), func(t *testing.T) {
+			if actual := test.result.Symbol(); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+	if actual := Failed.Render(false); actual != "✘ Failed" {
+		t.Errorf("expected %q, got %q", "✘ Failed", actual)
+	}
+	if actual := Failed.Render(true); actual != "\033[31m✘ Failed\033[0m" {
+		t.Errorf("expected a red rendering, got %q", actual)
+	}
+}
@@ -0,0 +1,77 @@
+package layer4
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RevertPolicy configures how Cleanup responds when a change cannot be reverted
+type RevertPolicy struct {
+	Attempts        int           `json:"attempts" yaml:"attempts"`               // Attempts is the maximum number of times each revert is tried; values below 2 disable retries
+	Backoff         time.Duration `json:"backoff" yaml:"backoff"`                 // Backoff is the delay before the first retry, which doubles before each subsequent retry
+	Max_Backoff     time.Duration `json:"max-backoff" yaml:"max-backoff"`         // Max_Backoff caps the delay before any retry; defaults to DefaultMaxBackoff
+	Stop_On_Failure bool          `json:"stop-on-failure" yaml:"stop-on-failure"` // Stop_On_Failure stops reverting the remaining changes once one cannot be reverted; otherwise every change is attempted
+}
+
+// revert reverts an applied change, retrying failures as configured, and returns true if it was reverted.
+// A change that reported an error without being applied cannot be retried and is treated as a failure.
+// Retries stop early if the context is done.
+func (p *RevertPolicy) revert(ctx context.Context, change *Change) bool {
+	for attempt := 1; ; attempt++ {
+		change.Revert()
+		if change.Reverted && change.Error == nil {
+			return true
+		}
+		if !change.Applied || attempt >= p.Attempts {
+			return false
+		}
+		if !sleep(ctx, backoff(p.Backoff, p.Max_Backoff, attempt)) {
+			return false
+		}
+		change.setError(nil)
+	}
+}
+
+// RevertChangesWithContext behaves like RevertChanges, but stops attempting further reverts once the context is done,
+// such as to cap how long cleanup may run. Every change that still needs reverting at that point has its Error set
+// and is counted as corrupted. Stopping early leaves those changes applied to their targets, so callers should
+// report them for manual remediation. Unlike RevertChanges, a failed revert does not prevent the remaining changes
+// from being attempted.
+func (a *Assessment) RevertChangesWithContext(ctx context.Context) (corrupted bool) {
+	return a.revertChangesWithPolicy(ctx, &RevertPolicy{})
+}
+
+// revertChangesWithPolicy reverts the Assessment's changes, retrying and continuing or stopping as the policy describes,
+// and skipping any remaining changes once the context is done. Changes are reverted in order of their names
+// so that Stop_On_Failure behaves predictably.
+func (a *Assessment) revertChangesWithPolicy(ctx context.Context, policy *RevertPolicy) (corrupted bool) {
+	for _, name := range a.changeNames() {
+		change := a.Changes[name]
+		if change.Reverted || (!change.Applied && change.Error == nil) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			if change.Error == nil {
+				change.setError(fmt.Errorf("revert was not attempted before cleanup stopped: %w", err))
+			}
+			corrupted = true
+			continue
+		}
+		if !policy.revert(ctx, change) {
+			corrupted = true
+			if policy.Stop_On_Failure {
+				return
+			}
+		}
+	}
+	for _, child := range a.Sub_Assessments {
+		if child.revertChangesWithPolicy(ctx, policy) {
+			corrupted = true
+			if policy.Stop_On_Failure {
+				return
+			}
+		}
+	}
+	return
+}
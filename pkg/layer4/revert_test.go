@@ -0,0 +1,168 @@
+package layer4
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRevertPolicy(t *testing.T) {
+	t.Run("Retry until success", func(t *testing.T) {
+		var attempts int
+		a := &Assessment{}
+		change := a.NewChange("change", "target", "description", nil, goodApplyFunc, func() error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("target is busy")
+			}
+			return nil
+		})
+		change.Apply()
+		c := &ControlEvaluation{
+			Assessments:   []*Assessment{a},
+			Revert_Policy: &RevertPolicy{Attempts: 3, Backoff: time.Millisecond},
+		}
+
+		c.Cleanup()
+
+		if attempts != 3 {
+			t.Errorf("Expected 3 revert attempts, but got %d", attempts)
+		}
+		if !change.Reverted || change.Error != nil {
+			t.Errorf("Expected the change to be reverted after retrying, but got reverted=%t, error=%v", change.Reverted, change.Error)
+		}
+		if c.Corrupted_State || len(c.Cleanup_Errors) != 0 {
+			t.Errorf("Expected a clean state after a successful retry, but got %v", c.Cleanup_Errors)
+		}
+	})
+	t.Run("Retries exhausted", func(t *testing.T) {
+		var attempts int
+		a := &Assessment{}
+		a.NewChange("change", "target", "description", nil, goodApplyFunc, func() error {
+			attempts++
+			return errors.New("target is busy")
+		}).Apply()
+		c := &ControlEvaluation{
+			Assessments:   []*Assessment{a},
+			Revert_Policy: &RevertPolicy{Attempts: 2},
+		}
+
+		c.Cleanup()
+
+		if attempts != 2 {
+			t.Errorf("Expected 2 revert attempts, but got %d", attempts)
+		}
+		if !c.Corrupted_State || len(c.Cleanup_Errors) != 1 {
+			t.Errorf("Expected the failed revert to be reported, but got %v", c.Cleanup_Errors)
+		}
+	})
+
+	t.Run("Backoff capped", func(t *testing.T) {
+		var attempts int
+		a := &Assessment{}
+		a.NewChange("change", "target", "description", nil, goodApplyFunc, func() error {
+			attempts++
+			return errors.New("target is busy")
+		}).Apply()
+		c := &ControlEvaluation{
+			Assessments:   []*Assessment{a},
+			Revert_Policy: &RevertPolicy{Attempts: 3, Backoff: time.Hour, Max_Backoff: time.Millisecond},
+		}
+
+		done := make(chan struct{})
+		go func() {
+			c.Cleanup()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected Max_Backoff to cap the delay between revert attempts")
+		}
+		if attempts != 3 {
+			t.Errorf("Expected 3 revert attempts, but got %d", attempts)
+		}
+	})
+
+	newControl := func(policy *RevertPolicy, reverts *int) *ControlEvaluation {
+		c := &ControlEvaluation{Revert_Policy: policy}
+		for _, id := range []string{"first", "second"} {
+			a := &Assessment{Requirement_Id: id}
+			a.NewChange("bad", "target", "description", nil, goodApplyFunc, func() error {
+				*reverts++
+				return errors.New("error")
+			}).Apply()
+			a.NewChange("good", "target", "description", nil, goodApplyFunc, func() error {
+				*reverts++
+				return nil
+			}).Apply()
+			c.Assessments = append(c.Assessments, a)
+		}
+		return c
+	}
+	t.Run("Stop on first failure", func(t *testing.T) {
+		var reverts int
+		c := newControl(&RevertPolicy{Stop_On_Failure: true}, &reverts)
+
+		c.Cleanup()
+
+		if reverts != 1 {
+			t.Errorf("Expected cleanup to stop after the first failed revert, but %d reverts were attempted", reverts)
+		}
+		if !c.Corrupted_State {
+			t.Errorf("Expected Corrupted_State to be true")
+		}
+		if c.Assessments[1].Changes["good"].Reverted {
+			t.Errorf("Expected the second assessment's changes not to be reverted")
+		}
+	})
+	t.Run("Continue after failure", func(t *testing.T) {
+		var reverts int
+		c := newControl(&RevertPolicy{}, &reverts)
+
+		c.Cleanup()
+
+		if reverts != 4 {
+			t.Errorf("Expected every change to be attempted, but %d reverts were attempted", reverts)
+		}
+		if !c.Assessments[0].Changes["good"].Reverted || !c.Assessments[1].Changes["good"].Reverted {
+			t.Errorf("Expected the good changes to be reverted despite the failures")
+		}
+		if len(c.Cleanup_Errors) != 2 {
+			t.Errorf("Expected 2 cleanup errors, but got %v", c.Cleanup_Errors)
+		}
+	})
+}
+
+func TestRevertChangesWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var reverts int
+	c := &ControlEvaluation{}
+	for _, id := range []string{"first", "second"} {
+		a := &Assessment{Requirement_Id: id}
+		a.NewChange("change", "target-"+id, "description", nil, goodApplyFunc, func() error {
+			reverts++
+			cancel()
+			return nil
+		}).Apply()
+		c.Assessments = append(c.Assessments, a)
+	}
+
+	c.CleanupWithContext(ctx)
+
+	first, second := c.Assessments[0].Changes["change"], c.Assessments[1].Changes["change"]
+	if reverts != 1 || !first.Reverted {
+		t.Errorf("Expected only the first change to be reverted before cancellation, but %d reverts ran", reverts)
+	}
+	if second.Reverted || !errors.Is(second.Error, context.Canceled) {
+		t.Errorf("Expected the second change to be marked as not reverted, but got reverted=%t, error=%v", second.Reverted, second.Error)
+	}
+	if second.Status() != ChangeFailed {
+		t.Errorf("Expected the second change to have status %s, but got %s", ChangeFailed, second.Status())
+	}
+	if !c.Corrupted_State || len(c.Cleanup_Errors) != 1 {
+		t.Errorf("Expected Corrupted_State with one cleanup error, but got %t and %v", c.Corrupted_State, c.Cleanup_Errors)
+	}
+}
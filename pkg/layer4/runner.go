@@ -0,0 +1,119 @@
+package layer4
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// EvaluateAll evaluates each control in order and returns the aggregate result across all of them.
+// If failFast is true, no further controls are evaluated once a control returns Failed.
+// Each evaluated control reverts its own changes as described by ControlEvaluation.Evaluate.
+func EvaluateAll(evals []*ControlEvaluation, targetData interface{}, userApplicability []string, changesAllowed bool, failFast bool) Result {
+	result := NotRun
+	for _, eval := range evals {
+		eval.Evaluate(targetData, userApplicability, changesAllowed)
+		result = UpdateAggregateResult(result, eval.Result)
+		if failFast && eval.Result == Failed {
+			break
+		}
+	}
+	return result
+}
+
+// ControlRunner evaluates many controls concurrently on a single bounded pool of workers
+type ControlRunner struct {
+	Max_Concurrency   int               // Max_Concurrency is the maximum number of controls evaluated at once, including their Setup, Teardown, and cleanup; values below 1 are treated as 1
+	Interrupt_Handler *InterruptHandler // Interrupt_Handler configures the response to termination signals received during Run, replacing the handlers of the individual controls
+}
+
+// NewControlRunner creates a ControlRunner that evaluates at most maxConcurrency controls at once
+func NewControlRunner(maxConcurrency int) *ControlRunner {
+	return &ControlRunner{Max_Concurrency: maxConcurrency}
+}
+
+// Run evaluates every control concurrently and returns once all of them are complete.
+// A fixed pool of Max_Concurrency workers takes the controls in order, and each worker evaluates one control at a time
+// from its Setup through its cleanup and Teardown, so hooks and reverts are bounded along with the assessments.
+// Assessments within a control run in order, so that halting and Depends_On behave as described by Evaluate.
+// A single interrupt handler is installed for the whole run; if a signal is received, every control is cleaned up
+// before the handler's OnSignal action is taken once.
+// The aggregate Result across all controls is returned, along with whether any control was left in a Corrupted_State.
+func (r *ControlRunner) Run(evals []*ControlEvaluation, targetData interface{}, userApplicability []string, changesAllowed bool) (result Result, corrupted bool) {
+	workers := r.Max_Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	stop := notifyInterrupt(r.Interrupt_Handler, func() { cleanupAll(evals) })
+	defer stop()
+	accumulator := &AggregateResultAccumulator{}
+	queue := make(chan *ControlEvaluation)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for eval := range queue {
+				_ = eval.evaluate(context.Background(), targetData, userApplicability, changesAllowed, evaluateOptions{sharedInterrupt: true})
+				accumulator.Add(eval.Result)
+				if eval.Corrupted_State {
+					mu.Lock()
+					corrupted = true
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, eval := range evals {
+		queue <- eval
+	}
+	close(queue)
+	wg.Wait()
+	return accumulator.Result(), corrupted
+}
+
+// cleanupAll cleans up each of the controls concurrently, returning once all of them are finished
+func cleanupAll(evals []*ControlEvaluation) {
+	var wg sync.WaitGroup
+	for _, eval := range evals {
+		wg.Add(1)
+		go func(eval *ControlEvaluation) {
+			defer wg.Done()
+			eval.Cleanup()
+		}(eval)
+	}
+	wg.Wait()
+}
+
+// EvaluateEach evaluates an independent clone of the control against each target, returning one
+// ControlEvaluation per target in the same order. Each clone has its own Changes, as described by Clone,
+// so a change applied for one target cannot affect another. The original control is left unchanged.
+func (c *ControlEvaluation) EvaluateEach(targets []interface{}, userApplicability []string, changesAllowed bool) []*ControlEvaluation {
+	evals := make([]*ControlEvaluation, 0, len(targets))
+	for _, target := range targets {
+		eval := c.Clone()
+		eval.Evaluate(target, userApplicability, changesAllowed)
+		evals = append(evals, eval)
+	}
+	return evals
+}
+
+// EvaluateByName behaves like EvaluateEach for a set of named targets, returning each target's
+// ControlEvaluation keyed by the target's name. Targets are evaluated in order of their names,
+// and each name is provided to context steps as the Target_Name of the EvaluationMetadata.
+func (c *ControlEvaluation) EvaluateByName(targets map[string]interface{}, userApplicability []string, changesAllowed bool) map[string]*ControlEvaluation {
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	evals := make(map[string]*ControlEvaluation, len(targets))
+	for _, name := range names {
+		eval := c.Clone()
+		ctx := WithMetadata(context.Background(), EvaluationMetadata{Target_Name: name})
+		_ = eval.EvaluateWithContext(ctx, targets[name], userApplicability, changesAllowed)
+		evals[name] = eval
+	}
+	return evals
+}
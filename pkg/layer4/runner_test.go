@@ -0,0 +1,262 @@
+package layer4
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newTestControl creates a control with a single assessment that runs the provided step
+func newTestControl(controlId string, step AssessmentStep) *ControlEvaluation {
+	return &ControlEvaluation{
+		Control_Id: controlId,
+		Assessments: []*Assessment{{
+			Requirement_Id: controlId + ".1",
+			Description:    "test assessment",
+			Applicability:  testingApplicability,
+			Steps:          []AssessmentStep{step},
+		}},
+	}
+}
+
+func TestEvaluateAll(t *testing.T) {
+	tests := []struct {
+		testName         string
+		failFast         bool
+		expectedResult   Result
+		expectedLastRuns Result
+	}{
+		{
+			testName:         "Fail fast",
+			failFast:         true,
+			expectedResult:   Failed,
+			expectedLastRuns: NotRun,
+		},
+		{
+			testName:         "Run all",
+			failFast:         false,
+			expectedResult:   Failed,
+			expectedLastRuns: Passed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			first := newTestControl("first", passingAssessmentStep)
+			first.Assessments[0].NewChange("change", "target", "description", nil, goodApplyFunc, goodRevertFunc).Apply()
+			evals := []*ControlEvaluation{
+				first,
+				newTestControl("middle", failingAssessmentStep),
+				newTestControl("last", passingAssessmentStep),
+			}
+
+			result := EvaluateAll(evals, nil, testingApplicability, true, test.failFast)
+			if result != test.expectedResult {
+				t.Errorf("expected %s, got %s", test.expectedResult, result)
+			}
+			if evals[2].Result != test.expectedLastRuns {
+				t.Errorf("expected the last control to be %s, got %s", test.expectedLastRuns, evals[2].Result)
+			}
+			if !first.Assessments[0].Changes["change"].Reverted {
+				t.Errorf("expected the controls that ran to be cleaned up")
+			}
+		})
+	}
+}
+
+// track increments active and records the peak it reaches
+func track(active, peak *int32) {
+	current := atomic.AddInt32(active, 1)
+	for {
+		previous := atomic.LoadInt32(peak)
+		if current <= previous || atomic.CompareAndSwapInt32(peak, previous, current) {
+			return
+		}
+	}
+}
+
+func TestControlRunner(t *testing.T) {
+	var active, peak, activeControls, peakControls int32
+	trackingStep := func(payload interface{}, _ map[string]*Change) (Result, string) {
+		track(&active, &peak)
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return Passed, "tracked"
+	}
+	var evals []*ControlEvaluation
+	for i := 0; i < 12; i++ {
+		eval := newTestControl(string(rune('A'+i)), trackingStep)
+		eval.AddAssessment(eval.Control_Id+".2", "test assessment", testingApplicability, []AssessmentStep{trackingStep})
+		eval.Setup = func() error {
+			track(&activeControls, &peakControls)
+			return nil
+		}
+		eval.Teardown = func() {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&activeControls, -1)
+		}
+		evals = append(evals, eval)
+	}
+	corruptedControl := newTestControl("corrupted", passingAssessmentStep)
+	corruptedControl.Assessments[0].NewChange("change", "target", "description", nil, goodApplyFunc, badRevertFunc).Apply()
+	evals = append(evals, corruptedControl)
+
+	result, corrupted := NewControlRunner(3).Run(evals, nil, testingApplicability, true)
+
+	if peak > 3 {
+		t.Errorf("Expected at most 3 assessments to run at once, but %d did", peak)
+	}
+	if peakControls > 3 {
+		t.Errorf("Expected at most 3 controls to be between Setup and Teardown at once, but %d were", peakControls)
+	}
+	for _, eval := range evals {
+		if eval.Result != Passed {
+			t.Errorf("Expected control %s to complete with %v, but got %v", eval.Control_Id, Passed, eval.Result)
+		}
+	}
+	if result != Passed {
+		t.Errorf("Expected the aggregate Result to be %v, but got %v", Passed, result)
+	}
+	if !corrupted {
+		t.Errorf("Expected the corrupted control to be reported")
+	}
+}
+
+func TestControlRunnerInterrupt(t *testing.T) {
+	signalled := make(chan struct{})
+	var received int32
+	var cleanedUp bool
+	var changes []*Change
+	runner := &ControlRunner{
+		Max_Concurrency: 2,
+		Interrupt_Handler: &InterruptHandler{
+			Signals: []os.Signal{syscall.SIGHUP},
+			OnSignal: func(os.Signal) {
+				atomic.AddInt32(&received, 1)
+				cleanedUp = changes[0].Reverted && changes[1].Reverted
+				close(signalled)
+			},
+		},
+	}
+	// each control waits for the signal so that both are in flight when every control is cleaned up
+	var started sync.WaitGroup
+	var evals []*ControlEvaluation
+	for _, id := range []string{"first", "second"} {
+		eval := newTestControl(id, passingAssessmentStep)
+		change := eval.Assessments[0].NewChange("change", id, "description", nil, goodApplyFunc, goodRevertFunc)
+		change.Apply()
+		changes = append(changes, change)
+		started.Add(1)
+		eval.Before_Assessment = func(*Assessment) {
+			started.Done()
+			<-signalled
+		}
+		eval.Interrupt_Handler = &InterruptHandler{
+			Signals: []os.Signal{syscall.SIGHUP},
+			OnSignal: func(os.Signal) {
+				t.Errorf("Expected the control's own interrupt handler not to be installed")
+			},
+		}
+		evals = append(evals, eval)
+	}
+	go func() {
+		started.Wait()
+		process, err := os.FindProcess(os.Getpid())
+		if err == nil {
+			err = process.Signal(syscall.SIGHUP)
+		}
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+			close(signalled)
+		}
+	}()
+
+	runner.Run(evals, nil, testingApplicability, true)
+
+	if atomic.LoadInt32(&received) != 1 {
+		t.Errorf("Expected the runner's action to be taken once, but it was taken %d times", received)
+	}
+	if !cleanedUp {
+		t.Errorf("Expected every control to be cleaned up before the runner's action was taken")
+	}
+}
+
+func TestEvaluateEach(t *testing.T) {
+	encrypted := func(payload interface{}, changes map[string]*Change) (Result, string) {
+		changes["tag"].Apply()
+		if payload.(map[string]bool)["encrypted"] {
+			return Passed, ""
+		}
+		return Failed, "not encrypted"
+	}
+	c := &ControlEvaluation{Name: "encryption", Control_Id: "encryption"}
+	c.AddAssessment("encrypted", "volume is encrypted", testingApplicability, []AssessmentStep{encrypted}).
+		NewChange("tag", "volume", "tag the volume", nil, goodApplyFunc, goodRevertFunc)
+
+	targets := []interface{}{map[string]bool{"encrypted": true}, map[string]bool{"encrypted": false}}
+	evals := c.EvaluateEach(targets, testingApplicability, true)
+
+	if len(evals) != 2 {
+		t.Fatalf("Expected 2 evaluations, but got %d", len(evals))
+	}
+	if evals[0].Result != Passed || evals[1].Result != Failed {
+		t.Errorf("Expected Passed and Failed, but got %s and %s", evals[0].Result, evals[1].Result)
+	}
+	first, second := evals[0].Assessments[0].Changes["tag"], evals[1].Assessments[0].Changes["tag"]
+	if first == second {
+		t.Fatalf("Expected each target to have its own changes")
+	}
+	if !first.Reverted || !second.Reverted {
+		t.Errorf("Expected each target's change to be applied and reverted")
+	}
+	if c.Result != NotRun || c.Assessments[0].Changes["tag"].Applied {
+		t.Errorf("Expected the original control to be left unchanged")
+	}
+}
+
+func TestEvaluateByName(t *testing.T) {
+	encrypted := func(ctx context.Context, payload interface{}, changes map[string]*Change) StepResult {
+		metadata, _ := MetadataFromContext(ctx)
+		if payload.(map[string]bool)["encrypted"] {
+			return StepResult{Result: Passed, Message: metadata.Target_Name}
+		}
+		return StepResult{Result: Failed, Message: metadata.Target_Name}
+	}
+	c := &ControlEvaluation{Name: "encryption", Control_Id: "encryption"}
+	c.Assessments = append(c.Assessments, &Assessment{
+		Requirement_Id: "encrypted",
+		Description:    "volume is encrypted",
+		Applicability:  testingApplicability,
+		Context_Steps:  []ContextStep{encrypted},
+	})
+
+	targets := map[string]interface{}{
+		"vol-1": map[string]bool{"encrypted": true},
+		"vol-2": map[string]bool{"encrypted": false},
+	}
+	evals := c.EvaluateByName(targets, testingApplicability, false)
+
+	if len(evals) != len(targets) {
+		t.Fatalf("Expected %d evaluations, but got %d", len(targets), len(evals))
+	}
+	for name := range targets {
+		if evals[name] == nil {
+			t.Fatalf("Expected an evaluation for %s", name)
+		}
+		if evals[name].Assessments[0].Message != name {
+			t.Errorf("Expected %s to be provided as the Target_Name, but got %q", name, evals[name].Assessments[0].Message)
+		}
+	}
+	if evals["vol-1"].Result != Passed || evals["vol-2"].Result != Failed {
+		t.Errorf("Expected Passed and Failed, but got %s and %s", evals["vol-1"].Result, evals["vol-2"].Result)
+	}
+	if c.Result != NotRun {
+		t.Errorf("Expected the original control to be left unchanged")
+	}
+	if evals := c.EvaluateByName(nil, testingApplicability, false); len(evals) != 0 {
+		t.Errorf("Expected no evaluations for no targets, but got %d", len(evals))
+	}
+}
@@ -0,0 +1,152 @@
+package layer4
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schemaDraft is the JSON Schema dialect written by GenerateSchema
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	bytesType     = reflect.TypeOf([]byte(nil))
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// enumValues lists the serialized values of each type that its MarshalJSON method encodes as a string enum,
+// rather than by its underlying kind
+var enumValues = map[reflect.Type]func() []string{
+	reflect.TypeOf(Result(0)):      resultValues,
+	reflect.TypeOf(RerunPolicy(0)): rerunPolicyValues,
+}
+
+// GenerateSchema writes a JSON Schema describing the serialized shape of a ControlEvaluation,
+// including its Assessments, Changes, and the allowed Result values.
+// The schema is derived from the structs themselves so that it stays in sync as fields are added.
+func GenerateSchema(w io.Writer) error {
+	defs := make(map[string]interface{})
+	root := schemaFor(reflect.TypeOf(ControlEvaluation{}), defs)
+	// the version is written by ControlEvaluation.MarshalJSON rather than stored in a field
+	control := defs["ControlEvaluation"].(map[string]interface{})
+	control["properties"].(map[string]interface{})["schema-version"] = map[string]interface{}{
+		"type": "string",
+		"enum": []string{SchemaVersion},
+	}
+	schema := map[string]interface{}{
+		"$schema": schemaDraft,
+		"$ref":    root["$ref"],
+		"$defs":   defs,
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(schema)
+}
+
+// resultValues returns the serialized value of each Result, in enum order
+func resultValues() []string {
+	values := make([]string, 0, len(toString))
+	for result := Result(0); int(result) < len(toString); result++ {
+		values = append(values, result.String())
+	}
+	return values
+}
+
+// rerunPolicyValues returns the serialized value of each RerunPolicy, in enum order
+func rerunPolicyValues() []string {
+	values := make([]string, 0, len(rerunPolicyToString))
+	for policy := RerunPolicy(0); int(policy) < len(rerunPolicyToString); policy++ {
+		values = append(values, policy.String())
+	}
+	return values
+}
+
+// nullable allows the schema to also match null, as encoding/json writes for nil slices, maps, and pointers
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if kind, ok := schema["type"].(string); ok {
+		schema["type"] = []string{kind, "null"}
+		return schema
+	}
+	if len(schema) == 0 {
+		return schema
+	}
+	return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+}
+
+// schemaFor returns the schema for a type, registering any struct and enum types in defs
+func schemaFor(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	if values, ok := enumValues[t]; ok {
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = map[string]interface{}{"type": "string", "enum": values()}
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	}
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case bytesType:
+		return nullable(map[string]interface{}{"type": "string", "contentEncoding": "base64"})
+	}
+	if t.Kind() != reflect.Func && t.Kind() != reflect.Struct && t.Kind() != reflect.Ptr && t.Implements(marshalerType) {
+		// any other type with its own encoding may be serialized as any value
+		return map[string]interface{}{}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(schemaFor(t.Elem(), defs))
+	case reflect.String, reflect.Func:
+		// function types, such as steps, are serialized by name
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice:
+		return nullable(map[string]interface{}{"type": "array", "items": schemaFor(t.Elem(), defs)})
+	case reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaFor(t.Elem(), defs)}
+	case reflect.Map:
+		return nullable(map[string]interface{}{"type": "object", "additionalProperties": schemaFor(t.Elem(), defs)})
+	case reflect.Struct:
+		ref := map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+		if _, ok := defs[t.Name()]; ok {
+			return ref
+		}
+		properties := make(map[string]interface{})
+		defs[t.Name()] = map[string]interface{}{"type": "object", "properties": properties}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, ok := jsonFieldName(field)
+			if !ok {
+				continue
+			}
+			properties[name] = schemaFor(field.Type, defs)
+		}
+		return ref
+	default:
+		// interfaces may hold any value
+		return map[string]interface{}{}
+	}
+}
+
+// jsonFieldName returns the name a struct field is serialized under by encoding/json,
+// or false if the field is not serialized
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
@@ -0,0 +1,540 @@
+package layer4
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSchema(t *testing.T) {
+	var buf bytes.Buffer
+	if err := GenerateSchema(&buf); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	var schema struct {
+		Ref  string `json:"$ref"`
+		Defs map[string]struct {
+			Enum       []string                   `json:"enum"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &schema); err != nil {
+		t.Fatalf("Expected the schema to be valid JSON, but got %v", err)
+	}
+
+	if schema.Ref != "#/$defs/ControlEvaluation" {
+		t.Errorf("Expected the root to reference ControlEvaluation, but got %s", schema.Ref)
+	}
+	enum := schema.Defs["Result"].Enum
+	if len(enum) != len(toString) {
+		t.Fatalf("Expected %d Result values, but got %d", len(toString), len(enum))
+	}
+	for _, value := range enum {
+		found := false
+		for _, expected := range toString {
+			if value == expected {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Unexpected Result value in schema: %s", value)
+		}
+	}
+	for _, def := range []string{"ControlEvaluation", "Assessment", "Change"} {
+		if _, ok := schema.Defs[def]; !ok {
+			t.Errorf("Expected the schema to define %s", def)
+		}
+	}
+	if _, ok := schema.Defs["Assessment"].Properties["Applicability_Matcher"]; ok {
+		t.Errorf("Expected fields excluded from JSON to be excluded from the schema")
+	}
+	if _, ok := schema.Defs["Assessment"].Properties["requirement-id"]; !ok {
+		t.Errorf("Expected the schema to use the serialized field names")
+	}
+}
+
+// validateSchema checks a decoded JSON value against the subset of JSON Schema written by GenerateSchema
+func validateSchema(value interface{}, schema map[string]interface{}, defs map[string]interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: unknown reference %s", path, ref)
+		}
+		return validateSchema(value, def, defs, path)
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		var errs []error
+		for _, option := range anyOf {
+			err := validateSchema(value, option.(map[string]interface{}), defs, path)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+	if kinds, ok := schema["type"]; ok {
+		var allowed []interface{}
+		if kind, ok := kinds.(string); ok {
+			allowed = []interface{}{kind}
+		} else {
+			allowed = kinds.([]interface{})
+		}
+		matched := false
+		for _, kind := range allowed {
+			switch v := value.(type) {
+			case nil:
+				matched = matched || kind == "null"
+			case bool:
+				matched = matched || kind == "boolean"
+			case string:
+				matched = matched || kind == "string"
+			case float64:
+				matched = matched || kind == "number" || (kind == "integer" && v == math.Trunc(v))
+			case []interface{}:
+				matched = matched || kind == "array"
+			case map[string]interface{}:
+				matched = matched || kind == "object"
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: expected %v, but got %v", path, kinds, value)
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			found = found || allowed == value
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+		}
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				if err := validateSchema(item, items, defs, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		for key, child := range v {
+			property, ok := properties[key].(map[string]interface{})
+			if !ok && properties != nil {
+				return fmt.Errorf("%s: unexpected property %s", path, key)
+			}
+			if !ok {
+				property = additional
+			}
+			if err := validateSchema(child, property, defs, path+"."+key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestGenerateSchemaMatchesSerialization(t *testing.T) {
+	var buf bytes.Buffer
+	if err := GenerateSchema(&buf); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	var schema struct {
+		Defs map[string]interface{} `json:"$defs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &schema); err != nil {
+		t.Fatalf("Expected the schema to be valid JSON, but got %v", err)
+	}
+
+	c := &ControlEvaluation{Control_Id: "schema", Revert_Policy: &RevertPolicy{Attempts: 2}}
+	a := c.AddAssessment("schema", "schema", testingApplicability, []AssessmentStep{passingAssessmentStep})
+	a.Context_Steps = []ContextStep{erroringContextStep}
+	a.Rerun_Policy = RerunSkip
+	a.Retry_Policy = &RetryPolicy{Attempts: 2}
+	a.SetLabel("team", "platform")
+	a.Sub_Assessments = []*Assessment{{Requirement_Id: "child", Description: "child", Applicability: testingApplicability, Steps: []AssessmentStep{passingAssessmentStep}}}
+	a.NewChange("change", "bucket", "make the bucket private", nil, goodApplyFunc, badRevertFunc)
+	a.Changes["change"].Apply()
+	c.Evaluate(nil, testingApplicability, true)
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	root := schema.Defs["ControlEvaluation"].(map[string]interface{})
+	if err := validateSchema(value, root, schema.Defs, "$"); err != nil {
+		t.Errorf("Expected the serialized control to match the schema, but got %v", err)
+	}
+	rerunPolicy := schema.Defs["Assessment"].(map[string]interface{})["properties"].(map[string]interface{})["rerun-policy"]
+	if err := validateSchema("Skip", rerunPolicy.(map[string]interface{}), schema.Defs, "$"); err != nil {
+		t.Errorf("Expected the rerun policy to be described by its string values, but got %v", err)
+	}
+}
+
+// cueExpr is a parsed expression from the subset of CUE used by schemas/layer-4.cue
+type cueExpr struct {
+	kind    string     // kind is one of string, int, bool, null, top, ref, literal, regex, list, struct, or disjunction
+	value   string     // value is the referenced definition, literal string, or regular expression
+	elem    *cueExpr   // elem is the type of each list element, or of each value matched by a struct's [string] pattern
+	fields  []cueField // fields are the fields declared by a struct
+	options []*cueExpr // options are the alternatives of a disjunction
+}
+
+// cueField is a field declared in a CUE struct
+type cueField struct {
+	name     string
+	optional bool
+	expr     *cueExpr
+}
+
+// cueParser parses the subset of CUE used by schemas/layer-4.cue
+type cueParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseCUE returns the top-level fields and definitions of a CUE file, keyed by name
+func parseCUE(source string) (map[string]*cueExpr, error) {
+	tokens, err := tokenizeCUE(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &cueParser{tokens: tokens}
+	file, err := p.parseFields()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q", p.tokens[p.pos])
+	}
+	defs := make(map[string]*cueExpr)
+	for _, field := range file.fields {
+		defs[field.name] = field.expr
+	}
+	return defs, nil
+}
+
+// tokenizeCUE splits CUE source into tokens, dropping whitespace, commas, and comments
+func tokenizeCUE(source string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(source); {
+		switch c := source[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case strings.HasPrefix(source[i:], "//"):
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(source[i:], "..."), strings.HasPrefix(source[i:], "=~"):
+			n := 2
+			if c == '.' {
+				n = 3
+			}
+			tokens = append(tokens, source[i:i+n])
+			i += n
+		case strings.ContainsRune("{}[]:?|*", rune(c)):
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(source) && source[j] != '"' {
+				if source[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(source) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, source[i:j+1])
+			i = j + 1
+		default:
+			j := i
+			for j < len(source) && (source[j] == '#' || source[j] == '_' || source[j] == '-' ||
+				('a' <= source[j] && source[j] <= 'z') || ('A' <= source[j] && source[j] <= 'Z') || ('0' <= source[j] && source[j] <= '9')) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+			tokens = append(tokens, source[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func (p *cueParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *cueParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *cueParser) expect(token string) error {
+	if actual := p.next(); actual != token {
+		return fmt.Errorf("expected %q, but got %q", token, actual)
+	}
+	return nil
+}
+
+// parseFields parses struct fields until a closing brace or the end of the file
+func (p *cueParser) parseFields() (*cueExpr, error) {
+	s := &cueExpr{kind: "struct"}
+	for p.peek() != "}" && p.peek() != "" {
+		if p.peek() == "[" {
+			p.next()
+			if err := p.expect("string"); err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			elem, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			s.elem = elem
+			continue
+		}
+		field := cueField{name: p.next()}
+		if unquoted, err := strconv.Unquote(field.name); err == nil {
+			field.name = unquoted
+		}
+		if p.peek() == "?" {
+			p.next()
+			field.optional = true
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.name, err)
+		}
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.name, err)
+		}
+		field.expr = expr
+		s.fields = append(s.fields, field)
+	}
+	return s, nil
+}
+
+// parseExpr parses a disjunction of one or more terms, ignoring any default marker
+func (p *cueParser) parseExpr() (*cueExpr, error) {
+	var options []*cueExpr
+	for {
+		if p.peek() == "*" {
+			p.next()
+		}
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, term)
+		if p.peek() != "|" {
+			break
+		}
+		p.next()
+	}
+	if len(options) == 1 {
+		return options[0], nil
+	}
+	return &cueExpr{kind: "disjunction", options: options}, nil
+}
+
+func (p *cueParser) parseTerm() (*cueExpr, error) {
+	token := p.next()
+	switch {
+	case token == "{":
+		s, err := p.parseFields()
+		if err != nil {
+			return nil, err
+		}
+		return s, p.expect("}")
+	case token == "[":
+		if err := p.expect("..."); err != nil {
+			return nil, err
+		}
+		elem, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &cueExpr{kind: "list", elem: elem}, p.expect("]")
+	case token == "=~":
+		pattern, err := strconv.Unquote(p.next())
+		return &cueExpr{kind: "regex", value: pattern}, err
+	case strings.HasPrefix(token, `"`):
+		literal, err := strconv.Unquote(token)
+		return &cueExpr{kind: "literal", value: literal}, err
+	case token == "_":
+		return &cueExpr{kind: "top"}, nil
+	case token == "string" || token == "int" || token == "bool" || token == "null":
+		return &cueExpr{kind: token}, nil
+	case strings.HasPrefix(token, "#"):
+		return &cueExpr{kind: "ref", value: token}, nil
+	}
+	return nil, fmt.Errorf("unexpected token %q", token)
+}
+
+// validateCUE checks a decoded JSON value against a parsed CUE expression, treating definitions as closed
+func validateCUE(value interface{}, expr *cueExpr, defs map[string]*cueExpr, path string) error {
+	switch expr.kind {
+	case "top":
+		return nil
+	case "ref":
+		def, ok := defs[expr.value]
+		if !ok {
+			return fmt.Errorf("%s: unknown definition %s", path, expr.value)
+		}
+		return validateCUE(value, def, defs, path)
+	case "disjunction":
+		var errs []error
+		for _, option := range expr.options {
+			err := validateCUE(value, option, defs, path)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	case "null":
+		if value != nil {
+			return fmt.Errorf("%s: expected null, but got %v", path, value)
+		}
+	case "string", "literal", "regex":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected a string, but got %v", path, value)
+		}
+		if expr.kind == "literal" && s != expr.value {
+			return fmt.Errorf("%s: expected %q, but got %q", path, expr.value, s)
+		}
+		if expr.kind == "regex" && !regexp.MustCompile(expr.value).MatchString(s) {
+			return fmt.Errorf("%s: expected %q to match %s", path, s, expr.value)
+		}
+	case "int":
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			return fmt.Errorf("%s: expected an int, but got %v", path, value)
+		}
+	case "bool":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected a bool, but got %v", path, value)
+		}
+	case "list":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected a list, but got %v", path, value)
+		}
+		for i, item := range items {
+			if err := validateCUE(item, expr.elem, defs, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "struct":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected a struct, but got %v", path, value)
+		}
+		declared := make(map[string]bool)
+		for _, field := range expr.fields {
+			declared[field.name] = true
+			fieldValue, present := object[field.name]
+			if !present {
+				if !field.optional {
+					return fmt.Errorf("%s: missing required field %s", path, field.name)
+				}
+				continue
+			}
+			if err := validateCUE(fieldValue, field.expr, defs, path+"."+field.name); err != nil {
+				return err
+			}
+		}
+		for key, fieldValue := range object {
+			if declared[key] {
+				continue
+			}
+			if expr.elem == nil {
+				return fmt.Errorf("%s: field %s is not allowed", path, key)
+			}
+			if err := validateCUE(fieldValue, expr.elem, defs, path+"."+key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestCUESchemaMatchesSerialization(t *testing.T) {
+	source, err := os.ReadFile("../../schemas/layer-4.cue")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defs, err := parseCUE(string(source))
+	if err != nil {
+		t.Fatalf("Expected the CUE schema to parse, but got %v", err)
+	}
+
+	minimal := &ControlEvaluation{Control_Id: "minimal"}
+	minimal.AddAssessment("minimal", "minimal", testingApplicability, []AssessmentStep{passingAssessmentStep})
+
+	populated := &ControlEvaluation{Control_Id: "populated", Remediation_Guide: "https://example.com/guide", Revert_Policy: &RevertPolicy{Attempts: 2}}
+	populated.SetLabel("owner", "security")
+	a := populated.AddAssessment("populated", "populated", testingApplicability, []AssessmentStep{passingAssessmentStep})
+	a.Context_Steps = []ContextStep{erroringContextStep}
+	a.Rerun_Policy = RerunSkip
+	a.Retry_Policy = &RetryPolicy{Attempts: 2}
+	a.SetLabel("team", "platform")
+	a.Sub_Assessments = []*Assessment{{Requirement_Id: "child", Description: "child", Applicability: testingApplicability, Steps: []AssessmentStep{passingAssessmentStep}}}
+	a.NewChange("change", "bucket", "make the bucket private", nil, goodApplyFunc, badRevertFunc)
+	a.Changes["change"].Apply()
+
+	tests := []struct {
+		name    string
+		control *ControlEvaluation
+	}{
+		{name: "Not evaluated", control: &ControlEvaluation{Control_Id: "empty"}},
+		{name: "Minimal", control: minimal},
+		{name: "Populated", control: populated},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.name != "Not evaluated" {
+				test.control.Evaluate(nil, testingApplicability, true)
+			}
+			data, err := json.Marshal(test.control)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var value interface{}
+			if err := json.Unmarshal(data, &value); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := validateCUE(value, defs["#ControlEvaluation"], defs, "$"); err != nil {
+				t.Errorf("Expected the serialized control to match schemas/layer-4.cue, but got %v", err)
+			}
+		})
+	}
+}
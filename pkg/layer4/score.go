@@ -0,0 +1,62 @@
+package layer4
+
+// ComplianceScore summarizes assessment results across many control evaluations
+type ComplianceScore struct {
+	Passed        int     // Passed is the number of assessments that passed
+	Applicable    int     // Applicable is the number of assessments that ran and were not NotApplicable, used as the denominator
+	NotApplicable int     // NotApplicable is the number of assessments excluded from the score because they did not apply
+	NotRun        int     // NotRun is the number of assessments excluded from the score because they were never run, such as after a halt
+	Percentage    float64 // Percentage is Passed as a percentage of Applicable, or zero if no assessments were applicable
+}
+
+// Score computes a compliance percentage across evaluations, weighted by the number of assessments in each
+// so that a control with many assessments counts for more than one with few.
+// Assessments that are NotApplicable or NotRun are excluded and counted separately, while any other non-passing
+// result counts against the score.
+func Score(evals []*ControlEvaluation) (score ComplianceScore) {
+	for _, eval := range evals {
+		for _, assessment := range eval.Assessments {
+			if assessment.Result == NotRun {
+				score.NotRun++
+				continue
+			}
+			if !assessment.Result.IsApplicable() {
+				score.NotApplicable++
+				continue
+			}
+			score.Applicable++
+			if assessment.Result.IsPass() {
+				score.Passed++
+			}
+		}
+	}
+	if score.Applicable > 0 {
+		score.Percentage = float64(score.Passed) / float64(score.Applicable) * 100
+	}
+	return
+}
+
+// MeetsThreshold reports whether the result is no more severe than the threshold, using the SeverityRank ordering.
+// For example, with a threshold of NeedsReview, Passed and NeedsReview meet it while Unknown and Failed do not.
+// Passing results, as reported by IsPass, and NotApplicable always meet the threshold, so that an advisory Warning
+// or a control with nothing to check never fails a gate. NotRun never meets a threshold, since nothing was evaluated.
+func MeetsThreshold(result Result, threshold Result) bool {
+	switch {
+	case result == NotRun:
+		return false
+	case result == NotApplicable || result.IsPass():
+		return true
+	}
+	return result.SeverityRank() <= threshold.SeverityRank()
+}
+
+// ExitCode returns a process exit code for CI gating: 0 if every evaluation is Complete and its Result meets
+// the threshold, or 1 otherwise, so that an evaluation which did not run or stopped early fails the gate
+func ExitCode(evals []*ControlEvaluation, threshold Result) int {
+	for _, eval := range evals {
+		if !eval.Complete || !MeetsThreshold(eval.Result, threshold) {
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+package layer4
+
+import "testing"
+
+func TestScore(t *testing.T) {
+	withResults := func(results ...Result) *ControlEvaluation {
+		c := &ControlEvaluation{}
+		for _, result := range results {
+			c.Assessments = append(c.Assessments, &Assessment{Result: result})
+		}
+		return c
+	}
+	tests := []struct {
+		name     string
+		evals    []*ControlEvaluation
+		expected ComplianceScore
+	}{
+		{
+			name:     "All passing",
+			evals:    []*ControlEvaluation{withResults(Passed, Passed), withResults(Passed)},
+			expected: ComplianceScore{Passed: 3, Applicable: 3, Percentage: 100},
+		},
+		{
+			name:     "Mixed",
+			evals:    []*ControlEvaluation{withResults(Passed, Passed, Passed, Failed, NotApplicable), withResults(NeedsReview)},
+			expected: ComplianceScore{Passed: 3, Applicable: 5, NotApplicable: 1, Percentage: 60},
+		},
+		{
+			name:     "Not run",
+			evals:    []*ControlEvaluation{withResults(Passed, Failed, NotRun, NotRun)},
+			expected: ComplianceScore{Passed: 1, Applicable: 2, NotRun: 2, Percentage: 50},
+		},
+		{
+			name:     "All not applicable",
+			evals:    []*ControlEvaluation{withResults(NotApplicable), withResults(NotApplicable)},
+			expected: ComplianceScore{NotApplicable: 2},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			score := Score(test.evals)
+			if score != test.expected {
+				t.Errorf("Expected %+v, but got %+v", test.expected, score)
+			}
+		})
+	}
+}
+
+func TestMeetsThreshold(t *testing.T) {
+	tests := []struct {
+		result   Result
+		expected bool
+	}{
+		{result: Passed, expected: true},
+		{result: Warning, expected: true},
+		{result: NeedsReview, expected: true},
+		{result: Unknown, expected: false},
+		{result: Failed, expected: false},
+		{result: NotRun, expected: false},
+		{result: NotApplicable, expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.result.String(), func(t *testing.T) {
+			if actual := MeetsThreshold(test.result, NeedsReview); actual != test.expected {
+				t.Errorf("Expected MeetsThreshold(%s, %s) to be %t", test.result, NeedsReview, test.expected)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	evals := []*ControlEvaluation{{Result: Passed, Complete: true}, {Result: NeedsReview, Complete: true}}
+
+	if code := ExitCode(evals, NeedsReview); code != 0 {
+		t.Errorf("Expected exit code 0 when every result meets the threshold, but got %d", code)
+	}
+	if code := ExitCode(evals, Passed); code != 1 {
+		t.Errorf("Expected exit code 1 when a result is worse than the threshold, but got %d", code)
+	}
+	if code := ExitCode([]*ControlEvaluation{{Result: NotRun, Complete: true}}, Failed); code != 1 {
+		t.Errorf("Expected exit code 1 when an evaluation did not run, but got %d", code)
+	}
+	if code := ExitCode([]*ControlEvaluation{{Result: Passed}}, NeedsReview); code != 1 {
+		t.Errorf("Expected exit code 1 when an evaluation is not complete, but got %d", code)
+	}
+	if code := ExitCode([]*ControlEvaluation{{Control_Id: "never evaluated"}}, Failed); code != 1 {
+		t.Errorf("Expected exit code 1 for an evaluation that was never run, but got %d", code)
+	}
+	if code := ExitCode(nil, Passed); code != 0 {
+		t.Errorf("Expected exit code 0 for no evaluations, but got %d", code)
+	}
+	if code := ExitCode([]*ControlEvaluation{{Result: Warning, Complete: true}}, Passed); code != 0 {
+		t.Errorf("Expected exit code 0 for an advisory Warning, but got %d", code)
+	}
+
+	inapplicable := &ControlEvaluation{Control_Id: "inapplicable"}
+	inapplicable.AddAssessment("elsewhere", "elsewhere", []string{"elsewhere"}, []AssessmentStep{passingAssessmentStep})
+	inapplicable.Evaluate(nil, testingApplicability, false)
+	if inapplicable.Result != NotApplicable {
+		t.Errorf("Expected an evaluation where nothing applied to be %s, but got %s", NotApplicable, inapplicable.Result)
+	}
+	if code := ExitCode([]*ControlEvaluation{inapplicable}, Passed); code != 0 {
+		t.Errorf("Expected exit code 0 when no assessment applied, but got %d", code)
+	}
+}
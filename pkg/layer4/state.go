@@ -0,0 +1,77 @@
+package layer4
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// evaluationState is the serialized checkpoint of a partially completed ControlEvaluation
+type evaluationState struct {
+	Control_Id  string            `json:"control-id" yaml:"control-id"`
+	Assessments []assessmentState `json:"assessments" yaml:"assessments"`
+}
+
+// assessmentState is the serialized checkpoint of a single assessment
+type assessmentState struct {
+	Requirement_Id string `json:"requirement-id" yaml:"requirement-id"`
+	Result         Result `json:"result" yaml:"result"`
+	Message        string `json:"message" yaml:"message"`
+	Steps_Executed int    `json:"steps-executed" yaml:"steps-executed"`
+	Interrupted    bool   `json:"interrupted" yaml:"interrupted"`
+}
+
+// SaveState writes a checkpoint recording the Result of each assessment, so that an interrupted evaluation
+// can be continued later with LoadState and Resume. Steps and changes are not saved, since they are
+// defined by the code that constructs the ControlEvaluation.
+func (c *ControlEvaluation) SaveState(w io.Writer) error {
+	state := evaluationState{Control_Id: c.Control_Id}
+	for _, assessment := range c.Assessments {
+		state.Assessments = append(state.Assessments, assessmentState{
+			Requirement_Id: assessment.Requirement_Id,
+			Result:         assessment.Result,
+			Message:        assessment.Message,
+			Steps_Executed: assessment.Steps_Executed,
+			Interrupted:    assessment.Interrupted,
+		})
+	}
+	return json.NewEncoder(w).Encode(state)
+}
+
+// LoadState restores a checkpoint written by SaveState onto a ControlEvaluation that has been constructed
+// with the same assessments, matching them by Requirement_Id.
+// An error is returned if the checkpoint is for a different control or names an unknown assessment.
+func (c *ControlEvaluation) LoadState(r io.Reader) error {
+	var state evaluationState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return err
+	}
+	if state.Control_Id != c.Control_Id {
+		return fmt.Errorf("cannot load state for control %s into control %s", state.Control_Id, c.Control_Id)
+	}
+	byId := make(map[string]*Assessment)
+	for _, assessment := range c.Assessments {
+		byId[assessment.Requirement_Id] = assessment
+	}
+	for _, saved := range state.Assessments {
+		assessment, ok := byId[saved.Requirement_Id]
+		if !ok {
+			return fmt.Errorf("saved state references unknown assessment %s", saved.Requirement_Id)
+		}
+		assessment.Result = saved.Result
+		assessment.Message = saved.Message
+		assessment.Steps_Executed = saved.Steps_Executed
+		assessment.Interrupted = saved.Interrupted
+	}
+	return nil
+}
+
+// Resume behaves like TryEvaluate, including recovering from a panicking step, but skips any assessment that already has a Result other than NotRun,
+// such as those restored by LoadState, while still folding their results into the control's Result.
+// An assessment that was Interrupted by cancellation or its timeout is not considered complete,
+// so it is reset and run again.
+func (c *ControlEvaluation) Resume(targetData interface{}, userApplicability []string, changesAllowed bool) (err error) {
+	defer c.recoverPanic(&err)
+	return c.evaluate(context.Background(), targetData, userApplicability, changesAllowed, evaluateOptions{resume: true})
+}
@@ -0,0 +1,132 @@
+package layer4
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestSaveAndResume(t *testing.T) {
+	runs := make(map[string]int)
+	newControl := func(onFirst func()) *ControlEvaluation {
+		c := &ControlEvaluation{Control_Id: "CTRL-01"}
+		for _, id := range []string{"first", "second", "third"} {
+			id := id
+			c.AddAssessment(id, id, testingApplicability, []AssessmentStep{func(interface{}, map[string]*Change) (Result, string) {
+				runs[id]++
+				if id == "first" && onFirst != nil {
+					onFirst()
+				}
+				return Passed, id + " passed"
+			}})
+		}
+		return c
+	}
+
+	// interrupt the evaluation after the first assessment
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupted := newControl(cancel)
+	_ = interrupted.EvaluateWithContext(ctx, nil, testingApplicability, false)
+	var checkpoint bytes.Buffer
+	if err := interrupted.SaveState(&checkpoint); err != nil {
+		t.Fatalf("Expected no error saving state, but got %v", err)
+	}
+	if !bytes.Contains(checkpoint.Bytes(), []byte(`"requirement-id":"first"`)) {
+		t.Errorf("Expected the checkpoint to use the serialized field names, but got %s", checkpoint.String())
+	}
+
+	resumed := newControl(nil)
+	if err := resumed.LoadState(&checkpoint); err != nil {
+		t.Fatalf("Expected no error loading state, but got %v", err)
+	}
+	if resumed.Assessments[0].Result != Passed || resumed.Assessments[1].Result != NotRun {
+		t.Fatalf("Expected the checkpoint to restore the first result only, but got %v and %v", resumed.Assessments[0].Result, resumed.Assessments[1].Result)
+	}
+	if err := resumed.Resume(nil, testingApplicability, false); err != nil {
+		t.Fatalf("Expected no error resuming, but got %v", err)
+	}
+
+	if runs["first"] != 1 || runs["second"] != 1 || runs["third"] != 1 {
+		t.Errorf("Expected each assessment to run exactly once across both evaluations, but got %v", runs)
+	}
+	if resumed.Result != Passed || !resumed.Complete {
+		t.Errorf("Expected the resumed evaluation to complete with %v, but got %v", Passed, resumed.Result)
+	}
+
+	t.Run("Interrupted assessment", func(t *testing.T) {
+		steps := 0
+		newControl := func(cancel func()) *ControlEvaluation {
+			c := &ControlEvaluation{Control_Id: "CTRL-01"}
+			c.Assessments = []*Assessment{{
+				Requirement_Id: "interrupted",
+				Description:    "interrupted",
+				Applicability:  testingApplicability,
+				Steps: []AssessmentStep{
+					func(interface{}, map[string]*Change) (Result, string) {
+						steps++
+						if cancel != nil {
+							cancel()
+						}
+						return Passed, "first step passed"
+					},
+					func(interface{}, map[string]*Change) (Result, string) {
+						steps++
+						return Passed, "second step passed"
+					},
+				},
+			}}
+			return c
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		interrupted := newControl(cancel)
+		_ = interrupted.EvaluateWithContext(ctx, nil, testingApplicability, false)
+		if interrupted.Assessments[0].Result != Unknown || !interrupted.Assessments[0].Interrupted {
+			t.Fatalf("Expected the assessment to be interrupted as %v, but got %v", Unknown, interrupted.Assessments[0].Result)
+		}
+		var checkpoint bytes.Buffer
+		if err := interrupted.SaveState(&checkpoint); err != nil {
+			t.Fatalf("Expected no error saving state, but got %v", err)
+		}
+
+		resumed := newControl(nil)
+		if err := resumed.LoadState(&checkpoint); err != nil {
+			t.Fatalf("Expected no error loading state, but got %v", err)
+		}
+		if err := resumed.Resume(nil, testingApplicability, false); err != nil {
+			t.Fatalf("Expected no error resuming, but got %v", err)
+		}
+		if steps != 3 {
+			t.Errorf("Expected the interrupted assessment to be run again from the start, but %d steps ran", steps)
+		}
+		assessment := resumed.Assessments[0]
+		if assessment.Result != Passed || assessment.Interrupted || assessment.Steps_Executed != 2 {
+			t.Errorf("Expected the resumed assessment to pass, but got %v after %d steps", assessment.Result, assessment.Steps_Executed)
+		}
+		if resumed.Result != Passed || !resumed.Complete {
+			t.Errorf("Expected the resumed evaluation to complete with %v, but got %v", Passed, resumed.Result)
+		}
+	})
+
+	t.Run("Panicking step", func(t *testing.T) {
+		c := &ControlEvaluation{Control_Id: "CTRL-01"}
+		c.AddAssessment("panics", "panics", testingApplicability, []AssessmentStep{func(interface{}, map[string]*Change) (Result, string) {
+			panic("step exploded")
+		}})
+		err := c.Resume(nil, testingApplicability, false)
+		if err == nil || c.Result != Unknown {
+			t.Errorf("Expected the panic to be recovered as %v with an error, but got %v: %v", Unknown, c.Result, err)
+		}
+	})
+
+	t.Run("Different control", func(t *testing.T) {
+		var checkpoint bytes.Buffer
+		_ = newControl(nil).SaveState(&checkpoint)
+		other := &ControlEvaluation{Control_Id: "CTRL-02"}
+		if err := other.LoadState(&checkpoint); err == nil {
+			t.Errorf("Expected an error loading state for a different control")
+		}
+	})
+}
@@ -0,0 +1,193 @@
+package layer4
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// StepResult is the structured output of a ContextStep
+type StepResult struct {
+	Result   Result      // Result is the outcome of the step
+	Message  string      // Message is the human-readable result of the step
+	Error    error       // Error is any error encountered by the step, which is preserved in the Assessment's Step_Errors
+	Data     interface{} // Data is any structured output produced by the step, which is stored as the Assessment Value when not nil
+	Evidence []Evidence  // Evidence is any artifacts produced by the step, which are attached to the Assessment
+}
+
+// ContextStep is an alternative to AssessmentStep that receives a context for cancellation
+// and returns a StepResult, allowing steps to report errors and structured data.
+// A step may branch on the data produced by earlier steps by reading it with StepValue.
+type ContextStep func(ctx context.Context, payload interface{}, changes map[string]*Change) StepResult
+
+// stepValueKey is the context key under which the running Assessment is stored for StepValue
+type stepValueKey struct{}
+
+// withValue returns a context through which steps can read the Assessment's Value with StepValue
+func (a *Assessment) withValue(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stepValueKey{}, a)
+}
+
+// StepValue returns the Value of the running Assessment, which is the last non-nil StepResult.Data returned
+// by an earlier step, so that a ContextStep can use the output of a previous step without sharing a closure.
+// The Value is read when StepValue is called, and steps run in order, so it always reflects the steps before the caller.
+// Sub_Assessments see their own Value rather than their parent's. The second return value is false
+// if the context does not belong to a running assessment or no earlier step has produced any data.
+func StepValue(ctx context.Context) (interface{}, bool) {
+	a, ok := ctx.Value(stepValueKey{}).(*Assessment)
+	if !ok || a.Value == nil {
+		return nil, false
+	}
+	return a.Value, true
+}
+
+func (cs ContextStep) String() string {
+	if name, ok := decodedStepName(cs.identity()); ok {
+		return name
+	}
+	return functionName(cs)
+}
+
+func (cs ContextStep) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cs.String())
+}
+
+func (cs ContextStep) MarshalYAML() (interface{}, error) {
+	return cs.String(), nil
+}
+
+// UnmarshalJSON decodes a step serialized by its function name. The function itself cannot be decoded,
+// so the step is replaced by a placeholder that keeps the name for re-serialization and reports Unknown if it is run.
+func (cs *ContextStep) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	*cs = decodedContextStep(name)
+	return nil
+}
+
+var (
+	decodedStepsMu         sync.Mutex
+	decodedAssessmentSteps = make(map[string]AssessmentStep) // decodedAssessmentSteps holds the placeholder AssessmentStep for each decoded name
+	decodedContextSteps    = make(map[string]ContextStep)    // decodedContextSteps holds the placeholder ContextStep for each decoded name
+	decodedStepNames       = make(map[uintptr]string)        // decodedStepNames maps the identity of each placeholder to its decoded name
+)
+
+// decodedAssessmentStep returns the placeholder AssessmentStep for a step decoded by name, shared by every step with that name
+func decodedAssessmentStep(name string) AssessmentStep {
+	decodedStepsMu.Lock()
+	defer decodedStepsMu.Unlock()
+	if step, ok := decodedAssessmentSteps[name]; ok {
+		return step
+	}
+	step := AssessmentStep(func(interface{}, map[string]*Change) (Result, string) {
+		return Unknown, fmt.Sprintf("step %s was decoded from a report and cannot be run", name)
+	})
+	decodedAssessmentSteps[name] = step
+	decodedStepNames[step.identity()] = name
+	return step
+}
+
+// decodedContextStep returns the placeholder ContextStep for a step decoded by name, shared by every step with that name
+func decodedContextStep(name string) ContextStep {
+	decodedStepsMu.Lock()
+	defer decodedStepsMu.Unlock()
+	if step, ok := decodedContextSteps[name]; ok {
+		return step
+	}
+	step := ContextStep(func(context.Context, interface{}, map[string]*Change) StepResult {
+		return StepResult{Result: Unknown, Error: fmt.Errorf("step %s was decoded from a report and cannot be run", name)}
+	})
+	decodedContextSteps[name] = step
+	decodedStepNames[step.identity()] = name
+	return step
+}
+
+// decodedStepName returns the name a placeholder step was decoded from, or false if the step was not decoded
+func decodedStepName(identity uintptr) (string, bool) {
+	decodedStepsMu.Lock()
+	defer decodedStepsMu.Unlock()
+	name, ok := decodedStepNames[identity]
+	return name, ok
+}
+
+// DefaultMaxBackoff caps the delay between retries for a policy that does not set Max_Backoff
+const DefaultMaxBackoff = time.Minute
+
+// RetryPolicy configures how steps that return Failed are retried
+type RetryPolicy struct {
+	Attempts    int           `json:"attempts" yaml:"attempts"`       // Attempts is the maximum number of times a step is run; values below 2 disable retries
+	Backoff     time.Duration `json:"backoff" yaml:"backoff"`         // Backoff is the delay before the first retry, which doubles before each subsequent retry
+	Max_Backoff time.Duration `json:"max-backoff" yaml:"max-backoff"` // Max_Backoff caps the delay before any retry; defaults to DefaultMaxBackoff
+}
+
+// wait pauses before the provided retry attempt, returning false if the context is done first
+func (p *RetryPolicy) wait(ctx context.Context, retry int) bool {
+	return sleep(ctx, backoff(p.Backoff, p.Max_Backoff, retry))
+}
+
+// sleep pauses for the provided delay, returning false if the context is done first
+func sleep(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// backoff returns the delay before the provided retry, doubling the base delay before each retry after the first
+// without exceeding the maximum, or DefaultMaxBackoff if the maximum is not positive
+func backoff(base, max time.Duration, retry int) time.Duration {
+	if max <= 0 {
+		max = DefaultMaxBackoff
+	}
+	if base <= 0 {
+		return 0
+	}
+	delay := base
+	for i := 1; i < retry && delay < max; i++ {
+		if delay > max/2 {
+			return max
+		}
+		delay *= 2
+	}
+	return min(delay, max)
+}
+
+// identity returns the address of the step's function value. Copies of the same step share an identity,
+// while closures created separately from one function literal do not, unlike their code pointers.
+func (as AssessmentStep) identity() uintptr {
+	return *(*uintptr)(unsafe.Pointer(&as))
+}
+
+// identity returns the address of the step's function value, as described by AssessmentStep.identity
+func (cs ContextStep) identity() uintptr {
+	return *(*uintptr)(unsafe.Pointer(&cs))
+}
+
+// withContext adapts an AssessmentStep to the ContextStep signature so that both step types can be run the same way
+func (as AssessmentStep) withContext() ContextStep {
+	return func(ctx context.Context, payload interface{}, changes map[string]*Change) StepResult {
+		result, message := as(payload, changes)
+		return StepResult{Result: result, Message: message}
+	}
+}
+
+// functionName returns the fully qualified name of the provided function
+func functionName(fn interface{}) string {
+	// Get the function pointer correctly
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "<unknown function>"
+	}
+	return f.Name()
+}
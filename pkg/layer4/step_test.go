@@ -0,0 +1,286 @@
+package layer4
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func passingContextStep(ctx context.Context, payload interface{}, changes map[string]*Change) StepResult {
+	return StepResult{Result: Passed, Message: "context step passed"}
+}
+
+func erroringContextStep(ctx context.Context, payload interface{}, changes map[string]*Change) StepResult {
+	return StepResult{Result: NeedsReview, Error: errors.New("could not reach the API")}
+}
+
+// TestMixedStepTypes ensures that AssessmentSteps and ContextSteps can be run in the same Assessment
+func TestMixedStepTypes(t *testing.T) {
+	a, err := NewAssessment("mixed", "mixed step types", testingApplicability, []AssessmentStep{passingAssessmentStep})
+	if err != nil {
+		t.Fatalf("unexpected error creating assessment: %v", err)
+	}
+	a.AddContextStep(passingContextStep)
+	a.AddContextStep(erroringContextStep)
+
+	result := a.Run(nil, false)
+	if result != NeedsReview {
+		t.Errorf("expected %s, got %s", NeedsReview, result)
+	}
+	if a.Steps_Executed != 3 {
+		t.Errorf("expected 3 steps to be executed, got %d", a.Steps_Executed)
+	}
+	if a.Message != "could not reach the API" {
+		t.Errorf("expected the step error to be used as the message, got %q", a.Message)
+	}
+}
+
+// TestContextStepOnly ensures that an Assessment with only context steps passes precheck
+func TestContextStepOnly(t *testing.T) {
+	a := &Assessment{
+		Requirement_Id: "context-only",
+		Description:    "context steps only",
+		Applicability:  testingApplicability,
+		Context_Steps:  []ContextStep{passingContextStep},
+	}
+	if result := a.Run(nil, false); result != Passed {
+		t.Errorf("expected %s, got %s (%s)", Passed, result, a.Message)
+	}
+}
+
+var errPermissionDenied = errors.New("permission denied")
+
+func TestStepErrors(t *testing.T) {
+	permissionStep := func(ctx context.Context, payload interface{}, changes map[string]*Change) StepResult {
+		return StepResult{Result: NeedsReview, Error: fmt.Errorf("listing buckets: %w", errPermissionDenied)}
+	}
+	a := &Assessment{
+		Requirement_Id: "errors",
+		Description:    "step errors",
+		Applicability:  testingApplicability,
+		Steps:          []AssessmentStep{passingAssessmentStep},
+		Context_Steps:  []ContextStep{passingContextStep, permissionStep, erroringContextStep},
+		Sub_Assessments: []*Assessment{{
+			Requirement_Id: "child",
+			Description:    "child",
+			Applicability:  testingApplicability,
+			Context_Steps:  []ContextStep{permissionStep},
+		}},
+	}
+	a.Run(nil, false)
+
+	if len(a.Step_Errors) != 2 {
+		t.Fatalf("expected 2 step errors, got %d: %v", len(a.Step_Errors), a.Step_Errors)
+	}
+	if !errors.Is(a.Step_Errors[0], errPermissionDenied) {
+		t.Errorf("expected the first step error to wrap the sentinel, got %v", a.Step_Errors[0])
+	}
+	if a.Step_Errors[0].Error() != "step 3: listing buckets: permission denied" {
+		t.Errorf("expected the step error to name the step, got %q", a.Step_Errors[0].Error())
+	}
+	if errors.Is(a.Step_Errors[1], errPermissionDenied) {
+		t.Errorf("expected the second step error not to match the sentinel")
+	}
+	if !errors.Is(a.Err(), errPermissionDenied) {
+		t.Errorf("expected Err to match the sentinel, got %v", a.Err())
+	}
+	if !errors.Is(a.Sub_Assessments[0].Err(), errPermissionDenied) {
+		t.Errorf("expected the sub-assessment error to be preserved")
+	}
+
+	data, err := json.Marshal(a.Normalized())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded Assessment
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("expected the step errors to survive a round trip, got %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Step_Error_Messages, a.Step_Error_Messages) || decoded.Step_Error_Messages[0] != "step 3: listing buckets: permission denied" {
+		t.Errorf("expected the step error messages to be serialized, got %v", decoded.Step_Error_Messages)
+	}
+	if decoded.Sub_Assessments[0].Step_Error_Messages[0] != "step 1: listing buckets: permission denied" {
+		t.Errorf("expected the sub-assessment step error messages to be serialized, got %v", decoded.Sub_Assessments[0].Step_Error_Messages)
+	}
+
+	a.Reset()
+	if a.Err() != nil || a.Step_Error_Messages != nil {
+		t.Errorf("expected Reset to clear the step errors, got %v", a.Err())
+	}
+	passing := &Assessment{Requirement_Id: "passing", Description: "passing", Applicability: testingApplicability, Steps: []AssessmentStep{passingAssessmentStep}}
+	passing.Run(nil, false)
+	if passing.Err() != nil {
+		t.Errorf("expected no error for a passing test, got %v", passing.Err())
+	}
+}
+
+func TestContextStepString(t *testing.T) {
+	expected := "github.com/revanite-io/sci/pkg/layer4.passingContextStep"
+	if ContextStep(passingContextStep).String() != expected {
+		t.Errorf("expected %q, got %q", expected, ContextStep(passingContextStep).String())
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		testName        string
+		attempts        int
+		expectedResult  Result
+		expectedRetries int
+		expectedCalls   int
+	}{
+		{
+			testName:        "No retries",
+			attempts:        0,
+			expectedResult:  Failed,
+			expectedRetries: 0,
+			expectedCalls:   1,
+		},
+		{
+			testName:        "Enough attempts to pass",
+			attempts:        3,
+			expectedResult:  Passed,
+			expectedRetries: 2,
+			expectedCalls:   3,
+		},
+		{
+			testName:        "Attempts exhausted",
+			attempts:        2,
+			expectedResult:  Failed,
+			expectedRetries: 1,
+			expectedCalls:   2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			calls := 0
+			flakyStep := func(interface{}, map[string]*Change) (Result, string) {
+				calls++
+				if calls <= 2 {
+					return Failed, "not ready yet"
+				}
+				return Passed, "ready"
+			}
+			a := &Assessment{
+				Requirement_Id: "flaky",
+				Description:    "flaky assessment",
+				Applicability:  testingApplicability,
+				Steps:          []AssessmentStep{flakyStep},
+				Retry_Policy:   &RetryPolicy{Attempts: test.attempts, Backoff: time.Millisecond},
+			}
+			result := a.Run(nil, false)
+			if result != test.expectedResult {
+				t.Errorf("expected %s, got %s", test.expectedResult, result)
+			}
+			if calls != test.expectedCalls {
+				t.Errorf("expected the step to be called %d times, got %d", test.expectedCalls, calls)
+			}
+			if a.Retries != test.expectedRetries || len(a.Retry_Messages) != test.expectedRetries {
+				t.Errorf("expected %d recorded retries, got %d retries and %d messages", test.expectedRetries, a.Retries, len(a.Retry_Messages))
+			}
+			if a.Steps_Executed != 1 {
+				t.Errorf("expected retries not to count as additional steps, got %d steps executed", a.Steps_Executed)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     time.Duration
+		max      time.Duration
+		retry    int
+		expected time.Duration
+	}{
+		{name: "First retry", base: time.Second, max: time.Hour, retry: 1, expected: time.Second},
+		{name: "Doubled", base: time.Second, max: time.Hour, retry: 3, expected: 4 * time.Second},
+		{name: "Capped", base: time.Second, max: 10 * time.Second, retry: 5, expected: 10 * time.Second},
+		{name: "Default cap", base: time.Second, retry: 10, expected: DefaultMaxBackoff},
+		{name: "Large retry", base: time.Second, max: time.Hour, retry: 100, expected: time.Hour},
+		{name: "No backoff", max: time.Hour, retry: 5, expected: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if delay := backoff(test.base, test.max, test.retry); delay != test.expected {
+				t.Errorf("expected a delay of %s, got %s", test.expected, delay)
+			}
+		})
+	}
+}
+
+func TestStepValue(t *testing.T) {
+	valueStep := func(value interface{}) ContextStep {
+		return func(ctx context.Context, payload interface{}, _ map[string]*Change) StepResult {
+			return StepResult{Result: Passed, Data: value}
+		}
+	}
+	tests := []struct {
+		name     string
+		steps    []ContextStep
+		expected interface{}
+	}{
+		{name: "Single value", steps: []ContextStep{valueStep(1)}, expected: 1},
+		{name: "Last value wins", steps: []ContextStep{valueStep(1), valueStep(2)}, expected: 2},
+		{name: "Nil does not overwrite", steps: []ContextStep{valueStep(1), valueStep(nil)}, expected: 1},
+		{name: "No value", steps: []ContextStep{valueStep(nil)}, expected: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := &Assessment{
+				Requirement_Id: "value",
+				Description:    "value",
+				Applicability:  testingApplicability,
+				Context_Steps:  test.steps,
+			}
+			a.Run(nil, false)
+			if a.Value != test.expected {
+				t.Errorf("Expected Value to be %v, but got %v", test.expected, a.Value)
+			}
+		})
+	}
+}
+
+func TestStepValueFromContext(t *testing.T) {
+	listBuckets := func(ctx context.Context, payload interface{}, _ map[string]*Change) StepResult {
+		if _, ok := StepValue(ctx); ok {
+			return StepResult{Result: Failed, Message: "expected no value before the first step produced one"}
+		}
+		return StepResult{Result: Passed, Data: []string{"public-bucket"}}
+	}
+	checkBuckets := func(ctx context.Context, payload interface{}, _ map[string]*Change) StepResult {
+		value, ok := StepValue(ctx)
+		if !ok {
+			return StepResult{Result: Unknown, Message: "no buckets were listed"}
+		}
+		if buckets := value.([]string); len(buckets) > 0 {
+			return StepResult{Result: Failed, Message: "found a public bucket: " + buckets[0]}
+		}
+		return StepResult{Result: Passed}
+	}
+	a := &Assessment{
+		Requirement_Id: "value",
+		Description:    "value",
+		Applicability:  testingApplicability,
+		Context_Steps:  []ContextStep{listBuckets, checkBuckets},
+	}
+	if result := a.Run(nil, false); result != Failed || a.Message != "found a public bucket: public-bucket" {
+		t.Errorf("Expected the second step to read the first step's output, but got %s (%s)", result, a.Message)
+	}
+
+	child := &Assessment{Requirement_Id: "child", Description: "child", Applicability: testingApplicability, Context_Steps: []ContextStep{checkBuckets}}
+	parent := &Assessment{Requirement_Id: "parent", Description: "parent", Applicability: testingApplicability, Sub_Assessments: []*Assessment{child}}
+	parent.Context_Steps = []ContextStep{func(ctx context.Context, payload interface{}, _ map[string]*Change) StepResult {
+		return StepResult{Result: Passed, Data: []string{"parent-bucket"}}
+	}}
+	if result := parent.Run(nil, false); result != Unknown || child.Message != "no buckets were listed" {
+		t.Errorf("Expected the sub-assessment not to see its parent's value, but got %s (%s)", result, child.Message)
+	}
+	if _, ok := StepValue(context.Background()); ok {
+		t.Errorf("Expected no value outside of a running assessment")
+	}
+}
@@ -0,0 +1,47 @@
+package layer4
+
+import (
+	"errors"
+	"fmt"
+)
+
+// StepFactory creates the step for a single parameter of an AssessmentTemplate
+type StepFactory func(param string) AssessmentStep
+
+// AssessmentTemplate describes a family of similar assessments that differ only by a parameter,
+// such as one assessment for each tag that a resource is required to have.
+type AssessmentTemplate struct {
+	Requirement_Id string   // Requirement_Id is the prefix of each generated requirement ID, which is suffixed with "-" and the parameter
+	Description    string   // Description is a format string given the parameter, such as "resource has the %s tag"
+	Applicability  []string // Applicability is copied to each generated assessment
+}
+
+// Build generates one assessment for each parameter, using the factory to create its step.
+// Every assessment is returned, along with an error describing any that are invalid, as with NewAssessment.
+func (t AssessmentTemplate) Build(params []string, factory StepFactory) ([]*Assessment, error) {
+	var assessments []*Assessment
+	var errs []error
+	for _, param := range params {
+		assessment, err := NewAssessment(t.requirementId(param), fmt.Sprintf(t.Description, param), append([]string(nil), t.Applicability...), []AssessmentStep{factory(param)})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("assessment for parameter %q is invalid: %w", param, err))
+		}
+		assessments = append(assessments, assessment)
+	}
+	return assessments, errors.Join(errs...)
+}
+
+// requirementId returns the requirement ID generated for the parameter
+func (t AssessmentTemplate) requirementId(param string) string {
+	return t.Requirement_Id + "-" + param
+}
+
+// AddTemplate adds one assessment for each parameter of the template, as described by AssessmentTemplate.Build.
+// Invalid assessments are handled in the same way as AddAssessment.
+func (c *ControlEvaluation) AddTemplate(template AssessmentTemplate, params []string, factory StepFactory) (assessments []*Assessment) {
+	for _, param := range params {
+		assessment := c.AddAssessment(template.requirementId(param), fmt.Sprintf(template.Description, param), append([]string(nil), template.Applicability...), []AssessmentStep{factory(param)})
+		assessments = append(assessments, assessment)
+	}
+	return
+}
@@ -0,0 +1,56 @@
+package layer4
+
+import "testing"
+
+func TestAssessmentTemplate(t *testing.T) {
+	template := AssessmentTemplate{
+		Requirement_Id: "CCC.TAG",
+		Description:    "resource has the %s tag",
+		Applicability:  testingApplicability,
+	}
+	requiredTag := func(tag string) AssessmentStep {
+		return func(payload interface{}, _ map[string]*Change) (Result, string) {
+			if _, ok := payload.(map[string]string)[tag]; !ok {
+				return Failed, "missing tag " + tag
+			}
+			return Passed, ""
+		}
+	}
+	params := []string{"owner", "environment", "cost-center"}
+
+	t.Run("Build", func(t *testing.T) {
+		assessments, err := template.Build(params, requiredTag)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(assessments) != 3 {
+			t.Fatalf("Expected 3 assessments, but got %d", len(assessments))
+		}
+		if assessments[1].Requirement_Id != "CCC.TAG-environment" || assessments[1].Description != "resource has the environment tag" {
+			t.Errorf("Expected the parameter in the requirement ID and description, but got %q and %q", assessments[1].Requirement_Id, assessments[1].Description)
+		}
+		target := map[string]string{"owner": "team", "environment": "prod"}
+		expected := []Result{Passed, Passed, Failed}
+		for i, assessment := range assessments {
+			if result := assessment.Run(target, false); result != expected[i] {
+				t.Errorf("Expected %s to be %s, but got %s", assessment.Requirement_Id, expected[i], result)
+			}
+		}
+	})
+	t.Run("Add to control evaluation", func(t *testing.T) {
+		c := &ControlEvaluation{Name: "tags", Control_Id: "tags"}
+		c.AddTemplate(template, params, requiredTag)
+		c.Evaluate(map[string]string{"owner": "team", "environment": "prod", "cost-center": "1234"}, testingApplicability, false)
+		if len(c.Assessments) != 3 || c.Result != Passed {
+			t.Errorf("Expected 3 passing assessments, but got %d with result %s", len(c.Assessments), c.Result)
+		}
+		if _, ok := c.GetAssessment("CCC.TAG-cost-center"); !ok {
+			t.Errorf("Expected the generated assessments to be indexed by requirement ID")
+		}
+	})
+	t.Run("Invalid template", func(t *testing.T) {
+		if _, err := (AssessmentTemplate{Description: "%s"}).Build([]string{"owner"}, requiredTag); err == nil {
+			t.Errorf("Expected an error for a template without applicability")
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package layer4
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SchemaVersion identifies the serialized format of a ControlEvaluation, and is written alongside its fields
+// as "schema-version". Version 2 writes the lowercase kebab-case field names described by schemas/layer-4.cue.
+// Earlier releases wrote the Go field names, such as "Control_Id", without any version.
+const SchemaVersion = "2"
+
+// ErrSchemaVersion is returned when unmarshalling a ControlEvaluation written in a format other than SchemaVersion,
+// including the unversioned format of earlier releases, whose fields would otherwise be silently ignored
+var ErrSchemaVersion = errors.New("unsupported schema version")
+
+// controlEvaluation has the fields of ControlEvaluation without its methods, so that it can be encoded by default
+type controlEvaluation ControlEvaluation
+
+// versionedControlEvaluation is the serialized form of a ControlEvaluation, which adds the SchemaVersion
+type versionedControlEvaluation struct {
+	Schema_Version     string `json:"schema-version" yaml:"schema-version"`
+	*controlEvaluation `yaml:",inline"`
+}
+
+// MarshalJSON writes the ControlEvaluation's fields along with the SchemaVersion
+func (c *ControlEvaluation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(versionedControlEvaluation{Schema_Version: SchemaVersion, controlEvaluation: (*controlEvaluation)(c)})
+}
+
+// MarshalYAML writes the ControlEvaluation's fields along with the SchemaVersion
+func (c *ControlEvaluation) MarshalYAML() (interface{}, error) {
+	return versionedControlEvaluation{Schema_Version: SchemaVersion, controlEvaluation: (*controlEvaluation)(c)}, nil
+}
+
+// UnmarshalJSON reads a ControlEvaluation written by MarshalJSON, returning ErrSchemaVersion if it was written
+// in any other format
+func (c *ControlEvaluation) UnmarshalJSON(data []byte) error {
+	versioned := versionedControlEvaluation{controlEvaluation: (*controlEvaluation)(c)}
+	if err := json.Unmarshal(data, &versioned); err != nil {
+		return err
+	}
+	if versioned.Schema_Version != SchemaVersion {
+		return fmt.Errorf("%w: %q, expected %q", ErrSchemaVersion, versioned.Schema_Version, SchemaVersion)
+	}
+	return nil
+}
@@ -5,6 +5,28 @@
 // Types
 
 #ControlEvaluation: {
+    "schema-version": "2"
+    name: string
+    "control-id": string
+    result: #Result
+    message: string
+    "corrupted-state"?: bool
+    "remediation-guide"?: "" | =~"^https?://[^\\s]+$"
+    assessments?: *null | [...#Assessment]
+    labels?: *null | {[string]: string}
+    "cleanup-error-messages"?: *null | [...string]
+    complete?: bool
+    "require-target-data"?: bool
+    "exclusive-change-targets"?: bool
+    "halt-on-unknown"?: bool
+    "revert-policy"?: *null | #RevertPolicy
+    "applicability-summary"?: #ApplicabilitySummary
+    "prefilter-applicability"?: bool
+}
+
+// #LegacyControlEvaluation is the unversioned format described before "schema-version" was introduced.
+// It is kept so that existing documents can still be validated, and is not written by the layer4 package.
+#LegacyControlEvaluation: {
     name: string
     "control-id": string
     result: #Result
@@ -19,17 +41,100 @@
     name: string
     description: string
     message: string
-    "function-address" string
-    change?: #Change
+    "function-address": string
+    change?: #LegacyChange
     value?: _
 }
 
-#Result: "Passed" | "Failed" | "Needs Review"
+#LegacyChange: {
+    "target-name": string
+    applied: bool
+    reverted: bool
+    error?: string
+    "target-object"?: _
+}
+
+#Assessment: {
+    "requirement-id": string
+    applicability: *null | [...string]
+    description: string
+    result: #Result
+    message: string
+    steps?: *null | [...string]
+    "context-steps"?: *null | [...string]
+    "steps-executed"?: int
+    "steps-total"?: int
+    "run-duration"?: string
+    value?: _
+    changes?: *null | {[string]: #Change}
+    "matched-applicability"?: *null | [...string]
+    "review-reason"?: string
+    "depends-on"?: *null | [...string]
+    "max-steps"?: int
+    evidence?: *null | [...#Evidence]
+    labels?: *null | {[string]: string}
+    "retry-policy"?: *null | #RetryPolicy
+    retries?: int
+    "retry-messages"?: *null | [...string]
+    "sub-assessments"?: *null | [...#Assessment]
+    output?: string
+    halted?: bool
+    "require-target-data"?: bool
+    remediation?: string
+    "halt-on-unknown"?: bool
+    "assessment-timeout"?: int
+    "rerun-policy"?: "Allowed" | "Skip" | "Error"
+    "not-applicable-to"?: *null | [...string]
+    "step-error-messages"?: *null | [...string]
+    interrupted?: bool
+}
+
+#Result: "Not Run" | "Passed" | "Failed" | "Needs Review" | "Not Applicable" | "Unknown" | "Warning"
 
 #Change: {
     "target-name": string
+    description: string
+    "target-object"?: _
     applied: bool
     reverted: bool
     error?: string
-    "target-object"?: _
-}
\ No newline at end of file
+    "rollback-window"?: int
+    "expires-at"?: string
+    "applied-at"?: string
+    "reverted-at"?: string
+    rejected?: bool
+}
+
+#Evidence: {
+    name: string
+    "content-type"?: string
+    content?: *null | string
+    uri?: string
+}
+
+#RetryPolicy: {
+    attempts: int
+    backoff: int
+    "max-backoff"?: int
+}
+
+#RevertPolicy: {
+    attempts: int
+    backoff: int
+    "max-backoff"?: int
+    "stop-on-failure": bool
+}
+
+#ApplicabilitySummary: {
+    provided: *null | [...string]
+    matched: int
+    skipped?: int
+    assessments: int
+}
+
+#Profile: {
+    name: string
+    version?: string
+    result: #Result
+    controls: *null | [...#ControlEvaluation]
+}